@@ -31,18 +31,22 @@ func RunMigrations(db *sql.DB) error {
 	}
 	sort.Strings(files)
 
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return err
+	}
+
 	for _, filename := range files {
+		if applied[filename] {
+			continue
+		}
+
 		content, err := os.ReadFile(filepath.Join(migrationsPath, filename))
 		if err != nil {
 			return fmt.Errorf("read migration %s: %w", filename, err)
 		}
 
-		sql := extractUpSection(string(content))
-		if sql == "" {
-			continue
-		}
-
-		if _, err := db.Exec(sql); err != nil {
+		if err := applyMigration(db, filename, extractUpSection(string(content))); err != nil {
 			return fmt.Errorf("execute migration %s: %w", filename, err)
 		}
 	}
@@ -50,6 +54,57 @@ func RunMigrations(db *sql.DB) error {
 	return nil
 }
 
+// appliedMigrations starts empty on databases created before schema_migrations;
+// the migrations up to 00008 are idempotent, so replaying them once is safe.
+func appliedMigrations(db *sql.DB) (map[string]bool, error) {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			filename   TEXT PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	rows, err := db.Query("SELECT filename FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("query schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := map[string]bool{}
+	for rows.Next() {
+		var filename string
+		if err := rows.Scan(&filename); err != nil {
+			return nil, fmt.Errorf("scan schema_migrations: %w", err)
+		}
+		applied[filename] = true
+	}
+
+	return applied, rows.Err()
+}
+
+func applyMigration(db *sql.DB, filename, query string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if query != "" {
+		if _, err := tx.Exec(query); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("INSERT INTO schema_migrations (filename) VALUES ($1)", filename); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func extractUpSection(content string) string {
 	lines := strings.Split(content, "\n")
 	var upLines []string
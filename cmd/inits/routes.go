@@ -3,6 +3,7 @@ package inits
 import (
 	"net/http"
 	"reviewer-service/internal/handlers"
+	"reviewer-service/internal/models"
 )
 
 func SetupRoutes(services *Services) http.Handler {
@@ -10,24 +11,67 @@ func SetupRoutes(services *Services) http.Handler {
 
 	// Handlers
 	teamsHandler := handlers.NewTeamsHandler(services.Teams)
-	usersHandler := handlers.NewUsersHandler(services.Users, services.PullRequests, services.Teams)
+	usersHandler := handlers.NewUsersHandler(services.Users, services.PullRequests, services.Teams, services.Bulk, services.Jobs)
 	prHandler := handlers.NewPullRequestsHandler(services.PullRequests, services.Users, services.Teams)
 	statsHandler := handlers.NewStatisticsHandler(services.Statistics)
+	jobsHandler := handlers.NewJobsHandler(services.Jobs)
+	metricsHandler := handlers.NewMetricsHandler(services.Statistics, services.Metrics)
+
+	// Background jobs
+	services.Jobs.Register(models.JobKindBulkDeactivateTeam, usersHandler.BulkDeactivateTeamJob)
+	services.Jobs.Register(models.JobKindBulkDeactivateUsers, usersHandler.BulkDeactivateUsersJob)
+	services.Jobs.Register(models.JobKindBulkReactivate, usersHandler.BulkReactivateJob)
 
 	// Routes
 	mux.HandleFunc("/team/add", teamsHandler.AddTeam)
 	mux.HandleFunc("/team/get", teamsHandler.GetTeam)
+	mux.HandleFunc("/team/setLead", teamsHandler.SetLead)
+	mux.HandleFunc("/team/setRole", teamsHandler.SetRole)
+	mux.HandleFunc("/team/setLeadReviewLabels", teamsHandler.SetLeadReviewLabels)
+	mux.HandleFunc("/team/settings", teamsHandler.GetSettings)
+	mux.HandleFunc("/team/settings/update", teamsHandler.UpdateSettings)
+	mux.HandleFunc("/team/settings/history", teamsHandler.GetSettingsHistory)
+	mux.HandleFunc("/team/addMembers", teamsHandler.AddMembers)
+	mux.HandleFunc("/team/removeMember", teamsHandler.RemoveMember)
+	mux.HandleFunc("/team/moveMember", teamsHandler.MoveMember)
+	mux.HandleFunc("/team/archive", teamsHandler.ArchiveTeam)
+	mux.HandleFunc("/team/delete", teamsHandler.DeleteTeam)
+	mux.HandleFunc("/team/rename", teamsHandler.RenameTeam)
+	mux.HandleFunc("/team/setParent", teamsHandler.SetParent)
+	mux.HandleFunc("/team/sync", teamsHandler.SyncTeams)
+	mux.HandleFunc("/team/export", teamsHandler.ExportTeams)
+	mux.HandleFunc("/team/import", teamsHandler.ImportTeams)
 
+	mux.HandleFunc("/users/get", usersHandler.GetUser)
+	mux.HandleFunc("/users/list", usersHandler.ListUsers)
 	mux.HandleFunc("/users/setIsActive", usersHandler.SetIsActive)
 	mux.HandleFunc("/users/getReview", usersHandler.GetReview)
+	mux.HandleFunc("/users/getAuthored", usersHandler.GetAuthored)
+	mux.HandleFunc("/users/setPrimaryTeam", usersHandler.SetPrimaryTeam)
 	mux.HandleFunc("/users/bulkDeactivateTeam", usersHandler.BulkDeactivateTeam)
+	mux.HandleFunc("/users/bulkDeactivate", usersHandler.BulkDeactivateUsers)
+	mux.HandleFunc("/users/bulkReactivate", usersHandler.BulkReactivate)
+	mux.HandleFunc("/users/erase", usersHandler.EraseUser)
+	mux.HandleFunc("/users/erasures", usersHandler.ListErasures)
 
 	mux.HandleFunc("/pullRequest/create", prHandler.CreatePR)
 	mux.HandleFunc("/pullRequest/merge", prHandler.MergePR)
 	mux.HandleFunc("/pullRequest/reassign", prHandler.ReassignReviewer)
+	mux.HandleFunc("/pullRequest/review", prHandler.SubmitReview)
+
+	mux.HandleFunc("/jobs/get", jobsHandler.GetJob)
+	mux.HandleFunc("/jobs/cancel", jobsHandler.CancelJob)
 
 	// Statistics
 	mux.HandleFunc("/statistics", statsHandler.GetStatistics)
+	mux.HandleFunc("/statistics/teams", statsHandler.GetTeamStatistics)
+	mux.HandleFunc("/statistics/latency", statsHandler.GetLatencyStatistics)
+	mux.HandleFunc("/statistics/fairness", statsHandler.GetFairnessStatistics)
+	mux.HandleFunc("/statistics/series", statsHandler.GetSeriesStatistics)
+	mux.HandleFunc("/statistics/interactions", statsHandler.GetInteractionStatistics)
+
+	// Metrics
+	mux.HandleFunc("/metrics", metricsHandler.Metrics)
 
 	// Health check
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -35,5 +79,5 @@ func SetupRoutes(services *Services) http.Handler {
 		w.Write([]byte("OK"))
 	})
 
-	return mux
+	return services.Metrics.Instrument(mux)
 }
@@ -2,10 +2,16 @@ package inits
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"fmt"
+	"os"
 
+	"reviewer-service/internal/metrics"
 	"reviewer-service/internal/models"
 	"reviewer-service/internal/repository/users"
+	srvBulk "reviewer-service/internal/services/bulk"
+	srvJobs "reviewer-service/internal/services/jobs"
 	srvPR "reviewer-service/internal/services/pullrequests"
 	srvStats "reviewer-service/internal/services/statistics"
 	srvTeams "reviewer-service/internal/services/teams"
@@ -20,6 +26,9 @@ type Services struct {
 	Teams        *srvTeams.Service
 	Users        *srvUsers.Service
 	Statistics   *srvStats.Service
+	Bulk         *srvBulk.Service
+	Jobs         *srvJobs.Service
+	Metrics      *metrics.Registry
 }
 
 type usersRepoAdapter struct {
@@ -34,20 +43,48 @@ func (a *usersRepoAdapter) GetUserByID(ctx context.Context, id string) (*models.
 	return a.repo.GetByID(ctx, userID)
 }
 
-func InitServices(db *sql.DB) *Services {
+// planTokenKey returns PLAN_TOKEN_KEY or, without it, a key generated once and
+// kept in the database, so that plans stay valid across restarts and replicas.
+func planTokenKey(db *sql.DB) ([]byte, error) {
+	if key := os.Getenv("PLAN_TOKEN_KEY"); key != "" {
+		return []byte(key), nil
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generate plan token key: %w", err)
+	}
+	return stPR.NewServiceKeysRepo(db).GetOrCreateKey(context.Background(), "plan_token", key)
+}
+
+func InitServices(db *sql.DB) (*Services, error) {
 	// Storage
 	prRepo := stPR.NewPullRequestsRepo(db)
 	teamsRepo := stPR.NewTeamsRepo(db)
 	usersRepo := stPR.NewUsersRepo(db)
 	statsRepo := stPR.NewStatisticsRepo(db)
+	jobsRepo := stPR.NewJobsRepo(db)
+	opsRepo := stPR.NewBulkOperationsRepo(db)
+	settingsRepo := stPR.NewTeamSettingsRepo(db)
 
 	// Адаптер для pullrequests service
 	usersRepoAdapter := &usersRepoAdapter{repo: usersRepo}
 
+	planKey, err := planTokenKey(db)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := metrics.New(db)
+	bulkService := srvBulk.New(prRepo, usersRepo, teamsRepo, opsRepo, settingsRepo, registry, planKey)
+
 	return &Services{
-		PullRequests: srvPR.New(prRepo, usersRepoAdapter),
-		Teams:        srvTeams.New(teamsRepo),
+		PullRequests: srvPR.New(prRepo, usersRepoAdapter, teamsRepo, settingsRepo, registry),
+		Teams:        srvTeams.New(teamsRepo, usersRepo, settingsRepo, bulkService),
 		Users:        srvUsers.New(usersRepo),
-		Statistics:   srvStats.New(statsRepo),
-	}
+		Statistics:   srvStats.New(statsRepo, teamsRepo),
+		Bulk:         bulkService,
+		Jobs:         srvJobs.New(jobsRepo),
+		Metrics:      registry,
+	}, nil
 }
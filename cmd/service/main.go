@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
@@ -30,10 +31,17 @@ func main() {
 	}
 	log.Println("Migrations applied successfully!")
 
-	services := inits.InitServices(db)
+	services, err := inits.InitServices(db)
+	if err != nil {
+		log.Fatalf("Failed to init services: %v", err)
+	}
 
 	handler := inits.SetupRoutes(services)
 
+	if err := services.Jobs.Resume(context.Background()); err != nil {
+		log.Printf("Failed to resume jobs: %v", err)
+	}
+
 	go func() {
 		if err := inits.StartServer(cfg, handler); err != nil {
 			log.Fatalf("Server failed: %v", err)
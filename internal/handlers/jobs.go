@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reviewer-service/internal/models"
+	srvJobs "reviewer-service/internal/services/jobs"
+	"time"
+)
+
+type JobsHandler struct {
+	jobsService *srvJobs.Service
+}
+
+func NewJobsHandler(jobsService *srvJobs.Service) *JobsHandler {
+	return &JobsHandler{jobsService: jobsService}
+}
+
+type JobInfo struct {
+	JobID      string           `json:"job_id"`
+	Kind       string           `json:"kind"`
+	Status     models.JobStatus `json:"status"`
+	Progress   int              `json:"progress"`
+	Total      int              `json:"total"`
+	Result     json.RawMessage  `json:"result,omitempty"`
+	Error      string           `json:"error,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+}
+
+type JobResponse struct {
+	Job JobInfo `json:"job"`
+}
+
+type CancelJobRequest struct {
+	JobID string `json:"job_id"`
+}
+
+func (h *JobsHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	jobID := r.URL.Query().Get("job_id")
+	if jobID == "" {
+		respondError(w, "INVALID_REQUEST", "job_id is required", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.jobsService.Get(r.Context(), jobID)
+	if err != nil {
+		respondJobError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(JobResponse{Job: newJobInfo(job)})
+}
+
+func (h *JobsHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req CancelJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.jobsService.Cancel(r.Context(), req.JobID)
+	if err != nil {
+		respondJobError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(JobResponse{Job: newJobInfo(job)})
+}
+
+func respondJobError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, srvJobs.ErrInvalidJobID):
+		respondError(w, "INVALID_REQUEST", "Invalid job_id", http.StatusBadRequest)
+	case errors.Is(err, models.ErrJobNotFound):
+		respondError(w, "NOT_FOUND", "job not found", http.StatusNotFound)
+	case errors.Is(err, srvJobs.ErrJobFinished):
+		respondError(w, "JOB_FINISHED", "job already finished", http.StatusConflict)
+	default:
+		respondError(w, "INTERNAL_ERROR", err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func respondJobAccepted(w http.ResponseWriter, job *models.Job) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(JobResponse{Job: newJobInfo(job)})
+}
+
+func newJobInfo(job *models.Job) JobInfo {
+	return JobInfo{
+		JobID:      job.ID,
+		Kind:       job.Kind,
+		Status:     job.Status,
+		Progress:   job.Progress,
+		Total:      job.Total,
+		Result:     job.Result,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		UpdatedAt:  job.UpdatedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"reviewer-service/internal/metrics"
+	"reviewer-service/internal/services/statistics"
+	"sync"
+	"time"
+)
+
+// statisticsMetricsTTL is how long scrapes are served the cached gauges.
+const statisticsMetricsTTL = time.Minute
+
+type MetricsHandler struct {
+	statsService *statistics.Service
+	registry     *metrics.Registry
+
+	mu        sync.Mutex
+	stats     []byte
+	updatedAt time.Time
+}
+
+func NewMetricsHandler(statsService *statistics.Service, registry *metrics.Registry) *MetricsHandler {
+	return &MetricsHandler{
+		statsService: statsService,
+		registry:     registry,
+	}
+}
+
+// Metrics writes the registry followed by gauges of the last 30 days. When
+// the statistics cannot be queried, the last computed gauges are served, or
+// none before the first success, so the registry is always scraped.
+func (h *MetricsHandler) Metrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stats, err := h.statisticsMetrics(r.Context())
+	if err != nil {
+		log.Printf("metrics: statistics gauges: %v", err)
+	}
+
+	w.Header().Set("Content-Type", metrics.ContentType)
+	h.registry.Write(w)
+	w.Write(stats)
+}
+
+func (h *MetricsHandler) statisticsMetrics(ctx context.Context) ([]byte, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	if h.stats != nil && now.Sub(h.updatedAt) < statisticsMetricsTTL {
+		return h.stats, nil
+	}
+
+	window, err := statistics.NewWindow(time.Time{}, time.Time{}, now)
+	if err != nil {
+		return h.stats, err
+	}
+	prStats, err := h.statsService.GetPRStats(ctx, statistics.Scope{}, window)
+	if err != nil {
+		return h.stats, err
+	}
+	teams, err := h.statsService.GetTeamStats(ctx, "", window)
+	if err != nil {
+		return h.stats, err
+	}
+
+	var buf bytes.Buffer
+	gauge := func(name, help string, value int) {
+		metrics.WriteFamily(&buf, name, "gauge", help, []metrics.Sample{{Value: float64(value)}})
+	}
+	gauge("reviewer_service_pull_requests_created", "Pull requests created in the last 30 days.", prStats.TotalPRs)
+	gauge("reviewer_service_pull_requests_open", "Pull requests created in the last 30 days that are still open.", prStats.OpenPRs)
+	gauge("reviewer_service_pull_requests_merged", "Pull requests merged in the last 30 days.", prStats.MergedPRs)
+	gauge("reviewer_service_assignments", "Reviewer assignments made in the last 30 days.", prStats.TotalAssignments)
+
+	samples := make([]metrics.Sample, 0, len(teams))
+	for _, team := range teams {
+		samples = append(samples, metrics.Sample{
+			Labels: []metrics.Label{{Name: "team", Value: team.TeamName}},
+			Value:  float64(team.Assignments),
+		})
+	}
+	metrics.WriteFamily(&buf, "reviewer_service_team_assignments", "gauge", "Reviewer assignments members of the team got in the last 30 days.", samples)
+
+	h.stats = buf.Bytes()
+	h.updatedAt = now
+	return h.stats, nil
+}
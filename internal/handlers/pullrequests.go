@@ -2,7 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
-	"math/rand"
+	"errors"
 	"net/http"
 	"reviewer-service/internal/models"
 	srvPR "reviewer-service/internal/services/pullrequests"
@@ -29,9 +29,10 @@ func NewPullRequestsHandler(prService *srvPR.Service, usersService *srvUsers.Ser
 }
 
 type CreatePRRequest struct {
-	PullRequestID   string `json:"pull_request_id"`
-	PullRequestName string `json:"pull_request_name"`
-	AuthorID        string `json:"author_id"`
+	PullRequestID   string   `json:"pull_request_id"`
+	PullRequestName string   `json:"pull_request_name"`
+	AuthorID        string   `json:"author_id"`
+	Labels          []string `json:"labels,omitempty"`
 }
 
 type PRResponse struct {
@@ -73,13 +74,20 @@ func (h *PullRequestsHandler) CreatePR(w http.ResponseWriter, r *http.Request) {
 		prID = uuid.New().String()
 	}
 
+	reviewers, err := h.prService.PickReviewers(r.Context(), team, req.AuthorID, req.Labels)
+	if err != nil {
+		respondError(w, "INTERNAL_ERROR", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	pr := models.PullRequest{
 		ID:        prID,
 		Name:      req.PullRequestName,
 		AuthorID:  req.AuthorID,
 		Status:    models.PullRequestStatusOpen,
 		CreatedAt: time.Now(),
-		Reviewers: []string{},
+		Labels:    req.Labels,
+		Reviewers: reviewers,
 	}
 
 	if err := h.prService.CreatePullRequest(r.Context(), pr); err != nil {
@@ -91,12 +99,6 @@ func (h *PullRequestsHandler) CreatePR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	reviewers := h.selectReviewers(team.Members, req.AuthorID, 2)
-	if len(reviewers) > 0 {
-		if err := h.prService.AssignReviewers(r.Context(), prID, reviewers); err != nil {
-		}
-	}
-
 	createdPR, err := h.prService.GetPullRequest(r.Context(), prID)
 	if err != nil {
 		respondError(w, "NOT_FOUND", "PR not found", http.StatusNotFound)
@@ -216,24 +218,16 @@ func (h *PullRequestsHandler) ReassignReviewer(w http.ResponseWriter, r *http.Re
 		excludeIDs[reviewerID] = true
 	}
 
-	var candidates []string
-	for _, member := range team.Members {
-		if !excludeIDs[member.ID] && member.IsActive {
-			candidates = append(candidates, member.ID)
-		}
+	newReviewerID, err := h.prService.PickReplacement(r.Context(), team, excludeIDs)
+	if err != nil {
+		respondError(w, "INTERNAL_ERROR", err.Error(), http.StatusInternalServerError)
+		return
 	}
-
-	if len(candidates) == 0 {
+	if newReviewerID == "" {
 		respondError(w, "NO_CANDIDATE", "no active replacement candidate in team", http.StatusConflict)
 		return
 	}
 
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(candidates), func(i, j int) {
-		candidates[i], candidates[j] = candidates[j], candidates[i]
-	})
-	newReviewerID := candidates[0]
-
 	if err := h.prService.ReassignReviewer(r.Context(), req.PullRequestID, req.OldUserID, newReviewerID); err != nil {
 		respondError(w, "INTERNAL_ERROR", "Failed to reassign reviewer", http.StatusInternalServerError)
 		return
@@ -260,29 +254,70 @@ func (h *PullRequestsHandler) ReassignReviewer(w http.ResponseWriter, r *http.Re
 	})
 }
 
-func (h *PullRequestsHandler) selectReviewers(members []models.User, excludeUserID string, maxCount int) []string {
-	var candidates []string
-	for _, member := range members {
-		if member.ID != excludeUserID && member.IsActive {
-			candidates = append(candidates, member.ID)
-		}
+type SubmitReviewRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	ReviewerID    string `json:"reviewer_id"`
+	Verdict       string `json:"verdict"`
+}
+
+type SubmitReviewResponse struct {
+	PullRequestID string     `json:"pull_request_id"`
+	ReviewerID    string     `json:"reviewer_id"`
+	Verdict       string     `json:"verdict"`
+	AssignedAt    time.Time  `json:"assigned_at"`
+	DecidedAt     *time.Time `json:"decided_at"`
+}
+
+// SubmitReview records an assigned reviewer's verdict on an open PR.
+func (h *PullRequestsHandler) SubmitReview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req SubmitReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid request body", http.StatusBadRequest)
+		return
 	}
 
-	if len(candidates) == 0 {
-		return []string{}
+	reviewerID, err := uuid.Parse(req.ReviewerID)
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid reviewer_id", http.StatusBadRequest)
+		return
 	}
 
-	if len(candidates) <= maxCount {
-		rand.Seed(time.Now().UnixNano())
-		rand.Shuffle(len(candidates), func(i, j int) {
-			candidates[i], candidates[j] = candidates[j], candidates[i]
-		})
-		return candidates
+	pr, err := h.prService.GetPullRequest(r.Context(), req.PullRequestID)
+	if err != nil || pr == nil {
+		respondError(w, "NOT_FOUND", "PR not found", http.StatusNotFound)
+		return
 	}
 
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(candidates), func(i, j int) {
-		candidates[i], candidates[j] = candidates[j], candidates[i]
+	if pr.Status == models.PullRequestStatusMerged {
+		respondError(w, "PR_MERGED", "cannot review merged PR", http.StatusConflict)
+		return
+	}
+
+	review, err := h.prService.SetVerdict(r.Context(), req.PullRequestID, reviewerID, models.ReviewVerdict(req.Verdict))
+	if err != nil {
+		if errors.Is(err, srvPR.ErrInvalidVerdict) {
+			respondError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+			return
+		}
+		respondError(w, "INTERNAL_ERROR", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if review == nil {
+		respondError(w, "NOT_ASSIGNED", "reviewer is not assigned to this PR", http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SubmitReviewResponse{
+		PullRequestID: req.PullRequestID,
+		ReviewerID:    review.ReviewerID,
+		Verdict:       string(review.Verdict),
+		AssignedAt:    review.AssignedAt,
+		DecidedAt:     review.DecidedAt,
 	})
-	return candidates[:maxCount]
 }
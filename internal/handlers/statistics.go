@@ -1,10 +1,17 @@
 package handlers
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"reviewer-service/internal/models"
 	"reviewer-service/internal/services/statistics"
 	"reviewer-service/internal/storage"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type StatisticsHandler struct {
@@ -16,8 +23,10 @@ func NewStatisticsHandler(statsService *statistics.Service) *StatisticsHandler {
 }
 
 type StatisticsResponse struct {
+	From            time.Time                     `json:"from"`
+	To              time.Time                     `json:"to"`
 	UserAssignments []storage.UserAssignmentStats `json:"user_assignments"`
-	PRStats         *storage.PRStats             `json:"pr_stats"`
+	PRStats         *storage.PRStats              `json:"pr_stats"`
 }
 
 func (h *StatisticsHandler) GetStatistics(w http.ResponseWriter, r *http.Request) {
@@ -26,22 +35,411 @@ func (h *StatisticsHandler) GetStatistics(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	userStats, err := h.statsService.GetUserAssignmentStats(r.Context())
+	scope := statistics.Scope{
+		TeamName:        r.URL.Query().Get("team_name"),
+		IncludeSubteams: r.URL.Query().Get("include_subteams") == "true",
+	}
+
+	window, err := parseWindow(r)
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userStats, err := h.statsService.GetUserAssignmentStats(r.Context(), scope, window)
 	if err != nil {
-		respondError(w, "INTERNAL_ERROR", err.Error(), http.StatusInternalServerError)
+		respondStatisticsError(w, err)
 		return
 	}
 
-	prStats, err := h.statsService.GetPRStats(r.Context())
+	prStats, err := h.statsService.GetPRStats(r.Context(), scope, window)
 	if err != nil {
-		respondError(w, "INTERNAL_ERROR", err.Error(), http.StatusInternalServerError)
+		respondStatisticsError(w, err)
+		return
+	}
+
+	if wantsCSV(r) {
+		writeStatisticsCSV(w, userStats, prStats)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(StatisticsResponse{
+		From:            window.From,
+		To:              window.To,
 		UserAssignments: userStats,
 		PRStats:         prStats,
 	})
 }
 
+func wantsCSV(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format == "csv"
+	}
+	return strings.Contains(r.Header.Get("Accept"), "text/csv")
+}
+
+func writeStatisticsCSV(w http.ResponseWriter, userStats []storage.UserAssignmentStats, prStats *storage.PRStats) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="statistics.csv"`)
+
+	out := csv.NewWriter(w)
+	out.Write([]string{"metric", "user_id", "username", "value"})
+	for _, row := range []struct {
+		metric string
+		value  int
+	}{
+		{"total_prs", prStats.TotalPRs},
+		{"open_prs", prStats.OpenPRs},
+		{"merged_prs", prStats.MergedPRs},
+		{"total_assignments", prStats.TotalAssignments},
+	} {
+		out.Write([]string{row.metric, "", "", strconv.Itoa(row.value)})
+	}
+	for _, stat := range userStats {
+		out.Write([]string{"assignments", stat.UserID, stat.Username, strconv.Itoa(stat.Count)})
+	}
+	out.Flush()
+}
+
+type TeamStatisticsResponse struct {
+	From  time.Time       `json:"from"`
+	To    time.Time       `json:"to"`
+	Teams []TeamStatsInfo `json:"teams"`
+}
+
+type TeamStatsInfo struct {
+	TeamName          string                  `json:"team_name"`
+	OpenedPRs         int                     `json:"opened_prs"`
+	OpenPRs           int                     `json:"open_prs"`
+	MergedPRs         int                     `json:"merged_prs"`
+	Assignments       int                     `json:"assignments"`
+	AvgReviewersPerPR float64                 `json:"avg_reviewers_per_pr"`
+	Members           []MemberAssignmentsInfo `json:"members"`
+}
+
+type MemberAssignmentsInfo struct {
+	UserID      string `json:"user_id"`
+	Username    string `json:"username"`
+	Assignments int    `json:"assignments"`
+}
+
+// GetTeamStatistics breaks the statistics of the window down by team.
+func (h *StatisticsHandler) GetTeamStatistics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	window, err := parseWindow(r)
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.statsService.GetTeamStats(r.Context(), r.URL.Query().Get("team_name"), window)
+	if err != nil {
+		respondStatisticsError(w, err)
+		return
+	}
+
+	resp := TeamStatisticsResponse{
+		From:  window.From,
+		To:    window.To,
+		Teams: make([]TeamStatsInfo, 0, len(stats)),
+	}
+	for _, stat := range stats {
+		info := TeamStatsInfo{
+			TeamName:          stat.TeamName,
+			OpenedPRs:         stat.OpenedPRs,
+			OpenPRs:           stat.OpenPRs,
+			MergedPRs:         stat.MergedPRs,
+			Assignments:       stat.Assignments,
+			AvgReviewersPerPR: stat.AvgReviewersPerPR,
+			Members:           make([]MemberAssignmentsInfo, 0, len(stat.Members)),
+		}
+		for _, m := range stat.Members {
+			info.Members = append(info.Members, MemberAssignmentsInfo{
+				UserID:      m.UserID,
+				Username:    m.Username,
+				Assignments: m.Count,
+			})
+		}
+		resp.Teams = append(resp.Teams, info)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// LatencyResponse holds latency percentiles in seconds.
+type LatencyResponse struct {
+	From      time.Time             `json:"from"`
+	To        time.Time             `json:"to"`
+	Overall   LatencyInfo           `json:"overall"`
+	Teams     []TeamLatencyInfo     `json:"teams"`
+	Reviewers []ReviewerLatencyInfo `json:"reviewers"`
+}
+
+type LatencyInfo struct {
+	ToAssignment PercentilesInfo `json:"to_assignment"`
+	ToVerdict    PercentilesInfo `json:"to_verdict"`
+	ToMerge      PercentilesInfo `json:"to_merge"`
+}
+
+type PercentilesInfo struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+}
+
+type TeamLatencyInfo struct {
+	TeamName string `json:"team_name"`
+	LatencyInfo
+}
+
+type ReviewerLatencyInfo struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	LatencyInfo
+}
+
+func (h *StatisticsHandler) GetLatencyStatistics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	window, err := parseWindow(r)
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	latency, err := h.statsService.GetLatency(r.Context(), r.URL.Query().Get("team_name"), window)
+	if err != nil {
+		respondStatisticsError(w, err)
+		return
+	}
+
+	resp := LatencyResponse{
+		From:      window.From,
+		To:        window.To,
+		Overall:   newLatencyInfo(latency.Overall),
+		Teams:     make([]TeamLatencyInfo, 0, len(latency.Teams)),
+		Reviewers: make([]ReviewerLatencyInfo, 0, len(latency.Reviewers)),
+	}
+	for _, team := range latency.Teams {
+		resp.Teams = append(resp.Teams, TeamLatencyInfo{
+			TeamName:    team.TeamName,
+			LatencyInfo: newLatencyInfo(team.LatencyStats),
+		})
+	}
+	for _, reviewer := range latency.Reviewers {
+		resp.Reviewers = append(resp.Reviewers, ReviewerLatencyInfo{
+			UserID:      reviewer.UserID,
+			Username:    reviewer.Username,
+			LatencyInfo: newLatencyInfo(reviewer.LatencyStats),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func newLatencyInfo(stats storage.LatencyStats) LatencyInfo {
+	return LatencyInfo{
+		ToAssignment: PercentilesInfo(stats.ToAssignment),
+		ToVerdict:    PercentilesInfo(stats.ToVerdict),
+		ToMerge:      PercentilesInfo(stats.ToMerge),
+	}
+}
+
+type FairnessResponse struct {
+	From  time.Time          `json:"from"`
+	To    time.Time          `json:"to"`
+	Teams []TeamFairnessInfo `json:"teams"`
+}
+
+type TeamFairnessInfo struct {
+	TeamName    string               `json:"team_name"`
+	Gini        float64              `json:"gini"`
+	MaxMinRatio *float64             `json:"max_min_ratio"`
+	Mean        float64              `json:"mean"`
+	StdDev      float64              `json:"std_dev"`
+	Members     []MemberFairnessInfo `json:"members"`
+	Above       []string             `json:"above"`
+	Below       []string             `json:"below"`
+	Excluded    []string             `json:"excluded"`
+}
+
+type MemberFairnessInfo struct {
+	UserID      string  `json:"user_id"`
+	Username    string  `json:"username"`
+	Assignments int     `json:"assignments"`
+	ActiveDays  float64 `json:"active_days"`
+	Rate        float64 `json:"rate"`
+	Deviation   float64 `json:"deviation"`
+}
+
+// GetFairnessStatistics reports how evenly reviews were spread within teams.
+func (h *StatisticsHandler) GetFairnessStatistics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	window, err := parseWindow(r)
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	threshold := statistics.DefaultFairnessThreshold
+	if value := r.URL.Query().Get("threshold"); value != "" {
+		threshold, err = strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(threshold) || math.IsInf(threshold, 0) || threshold <= 0 {
+			respondError(w, "INVALID_REQUEST", "Invalid threshold", http.StatusBadRequest)
+			return
+		}
+	}
+
+	teams, err := h.statsService.GetFairness(r.Context(), r.URL.Query().Get("team_name"), window, threshold)
+	if err != nil {
+		respondStatisticsError(w, err)
+		return
+	}
+
+	resp := FairnessResponse{
+		From:  window.From,
+		To:    window.To,
+		Teams: make([]TeamFairnessInfo, 0, len(teams)),
+	}
+	for _, team := range teams {
+		info := TeamFairnessInfo{
+			TeamName:    team.TeamName,
+			Gini:        team.Gini,
+			MaxMinRatio: team.MaxMinRatio,
+			Mean:        team.Mean,
+			StdDev:      team.StdDev,
+			Members:     make([]MemberFairnessInfo, 0, len(team.Members)),
+			Above:       team.Above,
+			Below:       team.Below,
+			Excluded:    team.Excluded,
+		}
+		for _, m := range team.Members {
+			info.Members = append(info.Members, MemberFairnessInfo(m))
+		}
+		resp.Teams = append(resp.Teams, info)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+type SeriesResponse struct {
+	From     time.Time         `json:"from"`
+	To       time.Time         `json:"to"`
+	Bucket   string            `json:"bucket"`
+	TimeZone string            `json:"time_zone"`
+	Points   []SeriesPointInfo `json:"points"`
+}
+
+type SeriesPointInfo struct {
+	Start       time.Time `json:"start"`
+	OpenedPRs   int       `json:"opened_prs"`
+	MergedPRs   int       `json:"merged_prs"`
+	Assignments int       `json:"assignments"`
+}
+
+func (h *StatisticsHandler) GetSeriesStatistics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	window, err := parseWindow(r)
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	req := statistics.SeriesRequest{
+		Window:   window,
+		Bucket:   statistics.Bucket(query.Get("bucket")),
+		TimeZone: query.Get("tz"),
+		TeamName: query.Get("team_name"),
+		UserID:   query.Get("user_id"),
+	}
+	if req.Bucket == "" {
+		req.Bucket = statistics.BucketDay
+	}
+	if req.TimeZone == "" {
+		req.TimeZone = "UTC"
+	}
+
+	points, err := h.statsService.GetSeries(r.Context(), req)
+	if err != nil {
+		respondStatisticsError(w, err)
+		return
+	}
+
+	resp := SeriesResponse{
+		From:     window.From,
+		To:       window.To,
+		Bucket:   string(req.Bucket),
+		TimeZone: req.TimeZone,
+		Points:   make([]SeriesPointInfo, 0, len(points)),
+	}
+	for _, p := range points {
+		resp.Points = append(resp.Points, SeriesPointInfo(p))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func respondStatisticsError(w http.ResponseWriter, err error) {
+	if errors.Is(err, models.ErrTeamNotFound) {
+		respondError(w, "NOT_FOUND", "team not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, statistics.ErrInvalidThreshold) || errors.Is(err, statistics.ErrInvalidSeries) {
+		respondError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+		return
+	}
+	respondError(w, "INTERNAL_ERROR", err.Error(), http.StatusInternalServerError)
+}
+
+// parseWindow accepts RFC 3339 times or dates; a date in to includes that whole day.
+func parseWindow(r *http.Request) (storage.Window, error) {
+	query := r.URL.Query()
+	from, err := parseStatisticsTime(query.Get("from"), false)
+	if err != nil {
+		return storage.Window{}, errors.New("Invalid from")
+	}
+	to, err := parseStatisticsTime(query.Get("to"), true)
+	if err != nil {
+		return storage.Window{}, errors.New("Invalid to")
+	}
+	return statistics.NewWindow(from, to, time.Now())
+}
+
+func parseStatisticsTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"reviewer-service/internal/services/statistics"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dotContentType     = "text/vnd.graphviz; charset=utf-8"
+	graphMLContentType = "application/graphml+xml; charset=utf-8"
+)
+
+type InteractionsResponse struct {
+	From  time.Time              `json:"from"`
+	To    time.Time              `json:"to"`
+	Teams []TeamInteractionsInfo `json:"teams"`
+}
+
+type TeamInteractionsInfo struct {
+	TeamName string                `json:"team_name"`
+	Users    []InteractionUserInfo `json:"users"`
+	Reviews  [][]int               `json:"reviews"`
+}
+
+type InteractionUserInfo struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// GetInteractionStatistics returns the author to reviewer matrix of each team.
+func (h *StatisticsHandler) GetInteractionStatistics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		accept := r.Header.Get("Accept")
+		switch {
+		case strings.Contains(accept, "text/vnd.graphviz"):
+			format = "dot"
+		case strings.Contains(accept, "graphml"):
+			format = "graphml"
+		default:
+			format = "json"
+		}
+	}
+	if format != "json" && format != "dot" && format != "graphml" {
+		respondError(w, "INVALID_REQUEST", "format must be json, dot or graphml", http.StatusBadRequest)
+		return
+	}
+
+	window, err := parseWindow(r)
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	teams, err := h.statsService.GetInteractions(r.Context(), r.URL.Query().Get("team_name"), window)
+	if err != nil {
+		respondStatisticsError(w, err)
+		return
+	}
+
+	switch format {
+	case "dot":
+		w.Header().Set("Content-Type", dotContentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="reviews.dot"`)
+		writeInteractionsDOT(w, teams)
+		return
+	case "graphml":
+		w.Header().Set("Content-Type", graphMLContentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="reviews.graphml"`)
+		writeInteractionsGraphML(w, teams)
+		return
+	}
+
+	resp := InteractionsResponse{
+		From:  window.From,
+		To:    window.To,
+		Teams: make([]TeamInteractionsInfo, 0, len(teams)),
+	}
+	for _, team := range teams {
+		info := TeamInteractionsInfo{
+			TeamName: team.TeamName,
+			Users:    make([]InteractionUserInfo, 0, len(team.Users)),
+			Reviews:  team.Reviews,
+		}
+		for _, u := range team.Users {
+			info.Users = append(info.Users, InteractionUserInfo(u))
+		}
+		resp.Teams = append(resp.Teams, info)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// interactionNodeID is per team, since a user can appear in several teams' graphs.
+func interactionNodeID(teamName, userID string) string {
+	return teamName + "/" + userID
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+func writeInteractionsDOT(w io.Writer, teams []statistics.TeamInteractions) {
+	fmt.Fprintln(w, "digraph reviews {")
+	for _, team := range teams {
+		fmt.Fprintf(w, "\tsubgraph %s {\n", dotQuote("cluster_"+team.TeamName))
+		fmt.Fprintf(w, "\t\tlabel=%s;\n", dotQuote(team.TeamName))
+		for _, u := range team.Users {
+			fmt.Fprintf(w, "\t\t%s [label=%s];\n", dotQuote(interactionNodeID(team.TeamName, u.UserID)), dotQuote(u.Username))
+		}
+		for i, row := range team.Reviews {
+			for j, reviews := range row {
+				if reviews == 0 {
+					continue
+				}
+				fmt.Fprintf(w, "\t\t%s -> %s [label=%d, weight=%d];\n",
+					dotQuote(interactionNodeID(team.TeamName, team.Users[i].UserID)),
+					dotQuote(interactionNodeID(team.TeamName, team.Users[j].UserID)),
+					reviews, reviews)
+			}
+		}
+		fmt.Fprintln(w, "\t}")
+	}
+	fmt.Fprintln(w, "}")
+}
+
+type graphML struct {
+	XMLName xml.Name       `xml:"graphml"`
+	XMLNS   string         `xml:"xmlns,attr"`
+	Keys    []graphMLKey   `xml:"key"`
+	Graphs  []graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func writeInteractionsGraphML(w io.Writer, teams []statistics.TeamInteractions) {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "user_id", For: "node", AttrName: "user_id", AttrType: "string"},
+			{ID: "username", For: "node", AttrName: "username", AttrType: "string"},
+			{ID: "reviews", For: "edge", AttrName: "reviews", AttrType: "int"},
+		},
+		Graphs: make([]graphMLGraph, 0, len(teams)),
+	}
+	for _, team := range teams {
+		graph := graphMLGraph{ID: team.TeamName, EdgeDefault: "directed"}
+		for _, u := range team.Users {
+			graph.Nodes = append(graph.Nodes, graphMLNode{
+				ID:   interactionNodeID(team.TeamName, u.UserID),
+				Data: []graphMLData{{Key: "user_id", Value: u.UserID}, {Key: "username", Value: u.Username}},
+			})
+		}
+		for i, row := range team.Reviews {
+			for j, reviews := range row {
+				if reviews == 0 {
+					continue
+				}
+				graph.Edges = append(graph.Edges, graphMLEdge{
+					Source: interactionNodeID(team.TeamName, team.Users[i].UserID),
+					Target: interactionNodeID(team.TeamName, team.Users[j].UserID),
+					Data:   []graphMLData{{Key: "reviews", Value: strconv.Itoa(reviews)}},
+				})
+			}
+		}
+		doc.Graphs = append(doc.Graphs, graph)
+	}
+
+	io.WriteString(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	enc.Encode(doc)
+	io.WriteString(w, "\n")
+}
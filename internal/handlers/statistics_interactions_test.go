@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"reviewer-service/internal/services/statistics"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// awkwardTeams has names that need escaping in both DOT and XML.
+func awkwardTeams() []statistics.TeamInteractions {
+	return []statistics.TeamInteractions{{
+		TeamName: `R&D <"core">`,
+		Users: []statistics.InteractionUser{
+			{UserID: "u1", Username: `Bob "the \ builder"`},
+			{UserID: "u2", Username: "Line\nBreak <&>"},
+		},
+		Reviews: [][]int{{0, 3}, {0, 0}},
+	}}
+}
+
+func TestDotQuote(t *testing.T) {
+	tests := map[string]string{
+		"plain":      `"plain"`,
+		`say "hi"`:   `"say \"hi\""`,
+		`back\slash`: `"back\\slash"`,
+		"two\nlines": `"two\nlines"`,
+		`\"`:         `"\\\""`,
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, dotQuote(in), "dotQuote(%q)", in)
+	}
+}
+
+func TestWriteInteractionsDOT(t *testing.T) {
+	var b strings.Builder
+	writeInteractionsDOT(&b, awkwardTeams())
+
+	want := `digraph reviews {
+	subgraph "cluster_R&D <\"core\">" {
+		label="R&D <\"core\">";
+		"R&D <\"core\">/u1" [label="Bob \"the \\ builder\""];
+		"R&D <\"core\">/u2" [label="Line\nBreak <&>"];
+		"R&D <\"core\">/u1" -> "R&D <\"core\">/u2" [label=3, weight=3];
+	}
+}
+`
+	assert.Equal(t, want, b.String())
+}
+
+func TestWriteInteractionsGraphMLEscapesNames(t *testing.T) {
+	var b strings.Builder
+	writeInteractionsGraphML(&b, awkwardTeams())
+
+	var doc graphML
+	require.NoError(t, xml.Unmarshal([]byte(b.String()), &doc))
+
+	require.Len(t, doc.Graphs, 1)
+	graph := doc.Graphs[0]
+	assert.Equal(t, `R&D <"core">`, graph.ID)
+
+	require.Len(t, graph.Nodes, 2)
+	assert.Equal(t, `R&D <"core">/u1`, graph.Nodes[0].ID)
+	assert.Equal(t, []graphMLData{{Key: "user_id", Value: "u1"}, {Key: "username", Value: `Bob "the \ builder"`}}, graph.Nodes[0].Data)
+	assert.Equal(t, "Line\nBreak <&>", graph.Nodes[1].Data[1].Value)
+
+	require.Len(t, graph.Edges, 1)
+	assert.Equal(t, graphMLEdge{
+		Source: `R&D <"core">/u1`,
+		Target: `R&D <"core">/u2`,
+		Data:   []graphMLData{{Key: "reviews", Value: "3"}},
+	}, graph.Edges[0])
+}
@@ -6,6 +6,8 @@ import (
 	"reviewer-service/internal/models"
 	srvTeams "reviewer-service/internal/services/teams"
 	"strings"
+
+	"github.com/google/uuid"
 )
 
 type TeamsHandler struct {
@@ -17,14 +19,16 @@ func NewTeamsHandler(teamsService *srvTeams.Service) *TeamsHandler {
 }
 
 type TeamRequest struct {
-	TeamName string      `json:"team_name"`
-	Members  []UserInput `json:"members"`
+	TeamName   string      `json:"team_name"`
+	ParentTeam string      `json:"parent_team,omitempty"`
+	Members    []UserInput `json:"members"`
 }
 
 type UserInput struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
 	IsActive bool   `json:"is_active"`
+	Role     string `json:"role,omitempty"`
 }
 
 type TeamResponse struct {
@@ -43,13 +47,10 @@ func (h *TeamsHandler) AddTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	members := make([]models.User, len(req.Members))
-	for i, m := range req.Members {
-		members[i] = models.User{
-			ID:       m.UserID,
-			Username: m.Username,
-			IsActive: m.IsActive,
-		}
+	members, err := toMembers(req.Members)
+	if err != nil {
+		respondTeamError(w, err)
+		return
 	}
 
 	team := models.Team{
@@ -57,6 +58,15 @@ func (h *TeamsHandler) AddTeam(w http.ResponseWriter, r *http.Request) {
 		Members: members,
 	}
 
+	if req.ParentTeam != "" {
+		parent, err := h.teamsService.GetTeam(r.Context(), req.ParentTeam)
+		if err != nil {
+			respondTeamError(w, err)
+			return
+		}
+		team.ParentID = parent.ID
+	}
+
 	if err := h.teamsService.CreateTeam(r.Context(), team); err != nil {
 		if strings.Contains(err.Error(), "team_name already exists") {
 			respondError(w, "TEAM_EXISTS", "team_name already exists", http.StatusBadRequest)
@@ -66,6 +76,10 @@ func (h *TeamsHandler) AddTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if created, err := h.teamsService.GetTeam(r.Context(), team.Name); err == nil {
+		team.ID = created.ID
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(TeamResponse{Team: team})
@@ -78,12 +92,24 @@ func (h *TeamsHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
 	}
 
 	teamName := r.URL.Query().Get("team_name")
-	if teamName == "" {
-		respondError(w, "INVALID_REQUEST", "team_name is required", http.StatusBadRequest)
+	teamID := r.URL.Query().Get("team_id")
+	if teamName == "" && teamID == "" {
+		respondError(w, "INVALID_REQUEST", "team_name or team_id is required", http.StatusBadRequest)
 		return
 	}
 
-	team, err := h.teamsService.GetTeam(r.Context(), teamName)
+	var team *models.Team
+	var err error
+	if teamID != "" {
+		id, parseErr := uuid.Parse(teamID)
+		if parseErr != nil {
+			respondError(w, "INVALID_REQUEST", "Invalid team_id", http.StatusBadRequest)
+			return
+		}
+		team, err = h.teamsService.GetTeamByID(r.Context(), id)
+	} else {
+		team, err = h.teamsService.GetTeam(r.Context(), teamName)
+	}
 	if err != nil {
 		respondError(w, "NOT_FOUND", "team not found", http.StatusNotFound)
 		return
@@ -93,7 +119,216 @@ func (h *TeamsHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.URL.Query().Get("include_subteams") == "true" {
+		if err := h.teamsService.LoadSubTeams(r.Context(), team); err != nil {
+			respondError(w, "INTERNAL_ERROR", err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(team)
 }
 
+type SetLeadRequest struct {
+	TeamName string `json:"team_name"`
+	UserID   string `json:"user_id"`
+}
+
+func (h *TeamsHandler) SetLead(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req SetLeadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid user_id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.teamsService.SetRole(r.Context(), req.TeamName, userID, models.TeamRoleLead); err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	team, err := h.teamsService.GetTeam(r.Context(), req.TeamName)
+	if err != nil {
+		respondError(w, "NOT_FOUND", "team not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TeamResponse{Team: *team})
+}
+
+type SetRoleRequest struct {
+	TeamName string `json:"team_name"`
+	UserID   string `json:"user_id"`
+	Role     string `json:"role"`
+}
+
+func (h *TeamsHandler) SetRole(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req SetRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid user_id", http.StatusBadRequest)
+		return
+	}
+
+	role, err := srvTeams.ParseRole(req.Role)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	if err := h.teamsService.SetRole(r.Context(), req.TeamName, userID, role); err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	team, err := h.teamsService.GetTeam(r.Context(), req.TeamName)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TeamResponse{Team: *team})
+}
+
+type SetLeadReviewLabelsRequest struct {
+	TeamName string   `json:"team_name"`
+	Labels   []string `json:"labels"`
+}
+
+func (h *TeamsHandler) SetLeadReviewLabels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req SetLeadReviewLabelsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	team, err := h.teamsService.SetLeadReviewLabels(r.Context(), req.TeamName, req.Labels)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TeamResponse{Team: *team})
+}
+
+func toMembers(inputs []UserInput) ([]models.User, error) {
+	members := make([]models.User, len(inputs))
+	for i, m := range inputs {
+		role, err := srvTeams.ParseRole(m.Role)
+		if err != nil {
+			return nil, err
+		}
+		members[i] = models.User{
+			ID:       m.UserID,
+			Username: m.Username,
+			IsActive: m.IsActive,
+			Role:     role,
+		}
+	}
+	return members, nil
+}
+
+type RenameTeamRequest struct {
+	TeamID      string `json:"team_id"`
+	TeamName    string `json:"team_name"`
+	NewTeamName string `json:"new_team_name"`
+}
+
+func (h *TeamsHandler) RenameTeam(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req RenameTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var teamID uuid.UUID
+	switch {
+	case req.TeamID != "":
+		id, err := uuid.Parse(req.TeamID)
+		if err != nil {
+			respondError(w, "INVALID_REQUEST", "Invalid team_id", http.StatusBadRequest)
+			return
+		}
+		teamID = id
+	case req.TeamName != "":
+		team, err := h.teamsService.GetTeam(r.Context(), req.TeamName)
+		if err != nil {
+			respondTeamError(w, err)
+			return
+		}
+		teamID = uuid.MustParse(team.ID)
+	default:
+		respondError(w, "INVALID_REQUEST", "team_name or team_id is required", http.StatusBadRequest)
+		return
+	}
+
+	team, err := h.teamsService.RenameTeam(r.Context(), teamID, req.NewTeamName)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TeamResponse{Team: *team})
+}
+
+type SetParentRequest struct {
+	TeamName   string `json:"team_name"`
+	ParentTeam string `json:"parent_team"`
+}
+
+func (h *TeamsHandler) SetParent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req SetParentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	team, err := h.teamsService.SetParent(r.Context(), req.TeamName, req.ParentTeam)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TeamResponse{Team: *team})
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	srvBulk "reviewer-service/internal/services/bulk"
+	"time"
+)
+
+type ArchiveTeamRequest struct {
+	TeamName   string `json:"team_name"`
+	Fallback   string `json:"fallback,omitempty"`
+	ApprovedBy string `json:"approved_by,omitempty"`
+}
+
+type ArchiveTeamResponse struct {
+	TeamName         string               `json:"team_name"`
+	ArchivedAt       *time.Time           `json:"archived_at"`
+	OperationID      string               `json:"operation_id,omitempty"`
+	DeactivatedUsers []string             `json:"deactivated_users"`
+	ReassignedPRs    []PRReassignmentInfo `json:"reassigned_prs"`
+	UnresolvedPRs    []UnresolvedPRInfo   `json:"unresolved_prs"`
+}
+
+type DeleteTeamRequest struct {
+	TeamName   string `json:"team_name"`
+	TargetTeam string `json:"target_team,omitempty"`
+	Fallback   string `json:"fallback,omitempty"`
+	ApprovedBy string `json:"approved_by,omitempty"`
+}
+
+type DeleteTeamResponse struct {
+	TeamName      string               `json:"team_name"`
+	TargetTeam    string               `json:"target_team,omitempty"`
+	DetachedUsers []string             `json:"detached_users"`
+	MovedPRs      []string             `json:"moved_prs"`
+	ReassignedPRs []PRReassignmentInfo `json:"reassigned_prs"`
+	UnresolvedPRs []UnresolvedPRInfo   `json:"unresolved_prs"`
+}
+
+func (h *TeamsHandler) ArchiveTeam(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ArchiveTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	fallback, err := srvBulk.ParseFallback(req.Fallback)
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	team, result, err := h.teamsService.Archive(r.Context(), req.TeamName, req.ApprovedBy, fallback)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ArchiveTeamResponse{
+		TeamName:         team.Name,
+		ArchivedAt:       team.ArchivedAt,
+		OperationID:      result.OperationID,
+		DeactivatedUsers: result.DeactivatedUsers,
+		ReassignedPRs:    toPRReassignmentInfos(result.ReassignedPRs),
+		UnresolvedPRs:    toUnresolvedPRInfos(result.UnresolvedPRs),
+	})
+}
+
+func (h *TeamsHandler) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req DeleteTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	fallback, err := srvBulk.ParseFallback(req.Fallback)
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.teamsService.Delete(r.Context(), req.TeamName, req.TargetTeam, req.ApprovedBy, fallback)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(DeleteTeamResponse{
+		TeamName:      result.TeamName,
+		TargetTeam:    result.TargetTeam,
+		DetachedUsers: result.DetachedUsers,
+		MovedPRs:      result.MovedPRs,
+		ReassignedPRs: toPRReassignmentInfos(result.ReassignedPRs),
+		UnresolvedPRs: toUnresolvedPRInfos(result.UnresolvedPRs),
+	})
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	srvTeams "reviewer-service/internal/services/teams"
+	"strconv"
+	"strings"
+)
+
+var teamCSVColumns = []string{"team_name", "user_id", "username", "role", "is_active"}
+
+type TeamImportResponse struct {
+	CreatedTeams []string `json:"created_teams"`
+	UpdatedTeams []string `json:"updated_teams"`
+	Users        int      `json:"users"`
+}
+
+type ImportErrorResponse struct {
+	Error ErrorDetail      `json:"error"`
+	Rows  []RowErrorDetail `json:"rows"`
+}
+
+type RowErrorDetail struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// ExportTeams writes one CSV row per member of every team that is not archived.
+func (h *TeamsHandler) ExportTeams(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	teams, err := h.teamsService.ExportTeams(r.Context())
+	if err != nil {
+		respondError(w, "INTERNAL_ERROR", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="teams.csv"`)
+
+	out := csv.NewWriter(w)
+	out.Write(teamCSVColumns)
+	for _, team := range teams {
+		if len(team.Members) == 0 {
+			out.Write([]string{team.Name, "", "", "", ""})
+		}
+		for _, m := range team.Members {
+			out.Write([]string{team.Name, m.ID, m.Username, string(m.Role), strconv.FormatBool(m.IsActive)})
+		}
+	}
+	out.Flush()
+}
+
+// ImportTeams upserts the users and memberships of a CSV body.
+func (h *TeamsHandler) ImportTeams(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rows, err := readImportRows(r.Body)
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid CSV: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.teamsService.Import(r.Context(), rows, r.URL.Query().Get("create_teams") == "true")
+	if err != nil {
+		var importErr *srvTeams.ImportError
+		if errors.As(err, &importErr) {
+			respondImportError(w, importErr)
+			return
+		}
+		respondTeamError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TeamImportResponse{
+		CreatedTeams: result.CreatedTeams,
+		UpdatedTeams: result.UpdatedTeams,
+		Users:        result.Users,
+	})
+}
+
+func readImportRows(body io.Reader) ([]srvTeams.ImportRow, error) {
+	in := csv.NewReader(body)
+	in.TrimLeadingSpace = true
+
+	header, err := in.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("missing header")
+		}
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		known := false
+		for _, column := range teamCSVColumns {
+			known = known || column == name
+		}
+		if !known {
+			return nil, errors.New("unknown column " + name)
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"team_name", "user_id", "username"} {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.New("missing column " + name)
+		}
+	}
+
+	var rows []srvTeams.ImportRow
+	for {
+		record, err := in.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		line, _ := in.FieldPos(0)
+		rows = append(rows, srvTeams.ImportRow{
+			Line:     line,
+			TeamName: field("team_name"),
+			UserID:   field("user_id"),
+			Username: field("username"),
+			IsActive: field("is_active"),
+			Role:     field("role"),
+		})
+	}
+	return rows, nil
+}
+
+func respondImportError(w http.ResponseWriter, err *srvTeams.ImportError) {
+	rows := make([]RowErrorDetail, 0, len(err.Rows))
+	for _, row := range err.Rows {
+		rows = append(rows, RowErrorDetail{Line: row.Line, Message: row.Message})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(ImportErrorResponse{
+		Error: ErrorDetail{
+			Code:    "INVALID_ROWS",
+			Message: err.Error(),
+		},
+		Rows: rows,
+	})
+}
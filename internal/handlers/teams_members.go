@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reviewer-service/internal/models"
+	srvBulk "reviewer-service/internal/services/bulk"
+	srvTeams "reviewer-service/internal/services/teams"
+
+	"github.com/google/uuid"
+)
+
+type RemoveMemberRequest struct {
+	TeamName   string `json:"team_name"`
+	UserID     string `json:"user_id"`
+	Fallback   string `json:"fallback,omitempty"`
+	ApprovedBy string `json:"approved_by,omitempty"`
+}
+
+type MoveMemberRequest struct {
+	UserID   string `json:"user_id"`
+	FromTeam string `json:"from_team,omitempty"`
+	TeamName string `json:"team_name"`
+	Fallback string `json:"fallback,omitempty"`
+}
+
+type MembershipChangeResponse struct {
+	UserID        string               `json:"user_id"`
+	FromTeam      string               `json:"from_team"`
+	ToTeam        string               `json:"to_team,omitempty"`
+	ReassignedPRs []PRReassignmentInfo `json:"reassigned_prs"`
+	UnresolvedPRs []UnresolvedPRInfo   `json:"unresolved_prs"`
+}
+
+func (h *TeamsHandler) AddMembers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req TeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	members, err := toMembers(req.Members)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	if err := h.teamsService.AddMembers(r.Context(), req.TeamName, members); err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	team, err := h.teamsService.GetTeam(r.Context(), req.TeamName)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TeamResponse{Team: *team})
+}
+
+func (h *TeamsHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req RemoveMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid user_id", http.StatusBadRequest)
+		return
+	}
+
+	fallback, err := srvBulk.ParseFallback(req.Fallback)
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	change, err := h.teamsService.RemoveMember(r.Context(), req.TeamName, userID, req.ApprovedBy, fallback)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newMembershipChangeResponse(change))
+}
+
+func (h *TeamsHandler) MoveMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req MoveMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid user_id", http.StatusBadRequest)
+		return
+	}
+
+	fallback, err := srvBulk.ParseFallback(req.Fallback)
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	change, err := h.teamsService.MoveMember(r.Context(), userID, req.FromTeam, req.TeamName, fallback)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newMembershipChangeResponse(change))
+}
+
+func respondTeamError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, srvTeams.ErrNoMembers), errors.Is(err, srvTeams.ErrSameTeam),
+		errors.Is(err, srvTeams.ErrEmptyName), errors.Is(err, srvTeams.ErrInvalidRole),
+		errors.Is(err, srvTeams.ErrInvalidSettings), errors.Is(err, srvTeams.ErrInvalidManifest),
+		errors.Is(err, models.ErrTeamCycle):
+		respondError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+	case errors.Is(err, models.ErrTeamNotFound), errors.Is(err, srvTeams.ErrUserNotFound),
+		errors.Is(err, models.ErrNotTeamMember):
+		respondError(w, "NOT_FOUND", err.Error(), http.StatusNotFound)
+	case errors.Is(err, models.ErrTeamExists):
+		respondError(w, "TEAM_EXISTS", err.Error(), http.StatusConflict)
+	case errors.Is(err, models.ErrTeamArchived):
+		respondError(w, "TEAM_ARCHIVED", err.Error(), http.StatusConflict)
+	case errors.Is(err, models.ErrSettingsVersionConflict):
+		respondError(w, "SETTINGS_CONFLICT", err.Error(), http.StatusConflict)
+	case errors.Is(err, srvBulk.ErrApprovalRequired), errors.Is(err, srvBulk.ErrNotApprover):
+		respondError(w, "APPROVAL_REQUIRED", err.Error(), http.StatusForbidden)
+	case errors.Is(err, srvTeams.ErrHasOpenPRs):
+		respondError(w, "TEAM_HAS_OPEN_PRS", err.Error(), http.StatusConflict)
+	case errors.Is(err, srvBulk.ErrPlanStale), errors.Is(err, models.ErrTeamChanged):
+		respondError(w, "PLAN_STALE", "teams changed since the plan was computed", http.StatusConflict)
+	default:
+		respondError(w, "INTERNAL_ERROR", err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func newMembershipChangeResponse(change *models.MembershipChange) MembershipChangeResponse {
+	return MembershipChangeResponse{
+		UserID:        change.UserID,
+		FromTeam:      change.FromTeam,
+		ToTeam:        change.ToTeam,
+		ReassignedPRs: toPRReassignmentInfos(change.ReassignedPRs),
+		UnresolvedPRs: toUnresolvedPRInfos(change.UnresolvedPRs),
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reviewer-service/internal/models"
+	"time"
+)
+
+// UpdateTeamSettingsRequest fields left out keep their current value.
+type UpdateTeamSettingsRequest struct {
+	TeamName string          `json:"team_name"`
+	Version  *int            `json:"version,omitempty"`
+	Settings json.RawMessage `json:"settings"`
+}
+
+type TeamSettingsResponse struct {
+	TeamName  string              `json:"team_name"`
+	Version   int                 `json:"version"`
+	Settings  models.TeamSettings `json:"settings"`
+	UpdatedAt *time.Time          `json:"updated_at,omitempty"`
+}
+
+type TeamSettingsHistoryResponse struct {
+	TeamName string                 `json:"team_name"`
+	Versions []TeamSettingsResponse `json:"versions"`
+}
+
+func (h *TeamsHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		respondError(w, "INVALID_REQUEST", "team_name is required", http.StatusBadRequest)
+		return
+	}
+
+	v, err := h.teamsService.GetSettings(r.Context(), teamName)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newTeamSettingsResponse(teamName, *v))
+}
+
+func (h *TeamsHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req UpdateTeamSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Settings) == 0 {
+		respondError(w, "INVALID_REQUEST", "settings is required", http.StatusBadRequest)
+		return
+	}
+
+	expectedVersion := -1
+	if req.Version != nil {
+		expectedVersion = *req.Version
+	}
+
+	v, err := h.teamsService.UpdateSettings(r.Context(), req.TeamName, expectedVersion, req.Settings)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newTeamSettingsResponse(req.TeamName, *v))
+}
+
+func (h *TeamsHandler) GetSettingsHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		respondError(w, "INVALID_REQUEST", "team_name is required", http.StatusBadRequest)
+		return
+	}
+
+	versions, err := h.teamsService.SettingsHistory(r.Context(), teamName)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	resp := TeamSettingsHistoryResponse{
+		TeamName: teamName,
+		Versions: make([]TeamSettingsResponse, 0, len(versions)),
+	}
+	for _, v := range versions {
+		resp.Versions = append(resp.Versions, newTeamSettingsResponse(teamName, v))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func newTeamSettingsResponse(teamName string, v models.TeamSettingsVersion) TeamSettingsResponse {
+	resp := TeamSettingsResponse{
+		TeamName: teamName,
+		Version:  v.Version,
+		Settings: v.Settings,
+	}
+	if v.Version > 0 {
+		updatedAt := v.CreatedAt
+		resp.UpdatedAt = &updatedAt
+	}
+	return resp
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reviewer-service/internal/models"
+	srvBulk "reviewer-service/internal/services/bulk"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TeamSyncResponse is the plan of a sync, or what was applied.
+type TeamSyncResponse struct {
+	Actions       []TeamSyncActionInfo `json:"actions"`
+	ReassignedPRs []PRReassignmentInfo `json:"reassigned_prs"`
+	UnresolvedPRs []UnresolvedPRInfo   `json:"unresolved_prs"`
+	DryRun        bool                 `json:"dry_run"`
+	PlanToken     string               `json:"plan_token"`
+}
+
+type TeamSyncActionInfo struct {
+	Action     string `json:"action"`
+	TeamName   string `json:"team_name,omitempty"`
+	ParentTeam string `json:"parent_team,omitempty"`
+	UserID     string `json:"user_id,omitempty"`
+	Username   string `json:"username,omitempty"`
+	FromTeam   string `json:"from_team,omitempty"`
+	Role       string `json:"role,omitempty"`
+}
+
+func (h *TeamsHandler) SyncTeams(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	manifest, err := decodeManifest(r)
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid manifest: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	fallback, err := srvBulk.ParseFallback(query.Get("fallback"))
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+		return
+	}
+	dryRun := query.Get("dry_run") == "true"
+
+	var sync *models.TeamSync
+	if dryRun {
+		sync, err = h.teamsService.PlanSync(r.Context(), manifest, fallback)
+	} else {
+		sync, err = h.teamsService.Sync(r.Context(), manifest, fallback, query.Get("plan_token"))
+	}
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newTeamSyncResponse(sync, dryRun))
+}
+
+// decodeManifest is strict, so a misspelt key fails instead of emptying a team.
+func decodeManifest(r *http.Request) (models.TeamManifest, error) {
+	var manifest models.TeamManifest
+	var err error
+	if strings.Contains(r.Header.Get("Content-Type"), "json") {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&manifest)
+	} else {
+		dec := yaml.NewDecoder(r.Body)
+		dec.KnownFields(true)
+		err = dec.Decode(&manifest)
+	}
+	return manifest, err
+}
+
+func newTeamSyncResponse(sync *models.TeamSync, dryRun bool) TeamSyncResponse {
+	actions := make([]TeamSyncActionInfo, 0, len(sync.Actions))
+	for _, a := range sync.Actions {
+		actions = append(actions, TeamSyncActionInfo{
+			Action:     string(a.Kind),
+			TeamName:   a.TeamName,
+			ParentTeam: a.ParentTeam,
+			UserID:     a.UserID,
+			Username:   a.Username,
+			FromTeam:   a.FromTeam,
+			Role:       string(a.Role),
+		})
+	}
+
+	return TeamSyncResponse{
+		Actions:       actions,
+		ReassignedPRs: toPRReassignmentInfos(sync.ReassignedPRs),
+		UnresolvedPRs: toUnresolvedPRInfos(sync.UnresolvedPRs),
+		DryRun:        dryRun,
+		PlanToken:     sync.PlanToken,
+	}
+}
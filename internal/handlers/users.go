@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"reviewer-service/internal/models"
+	srvBulk "reviewer-service/internal/services/bulk"
+	srvJobs "reviewer-service/internal/services/jobs"
 	srvPR "reviewer-service/internal/services/pullrequests"
 	srvTeams "reviewer-service/internal/services/teams"
 	srvUsers "reviewer-service/internal/services/users"
@@ -15,13 +17,17 @@ type UsersHandler struct {
 	usersService        *srvUsers.Service
 	pullRequestsService *srvPR.Service
 	teamsService        *srvTeams.Service
+	bulkService         *srvBulk.Service
+	jobsService         *srvJobs.Service
 }
 
-func NewUsersHandler(usersService *srvUsers.Service, prService *srvPR.Service, teamsService *srvTeams.Service) *UsersHandler {
+func NewUsersHandler(usersService *srvUsers.Service, prService *srvPR.Service, teamsService *srvTeams.Service, bulkService *srvBulk.Service, jobsService *srvJobs.Service) *UsersHandler {
 	return &UsersHandler{
 		usersService:        usersService,
 		pullRequestsService: prService,
 		teamsService:        teamsService,
+		bulkService:         bulkService,
+		jobsService:         jobsService,
 	}
 }
 
@@ -71,6 +77,56 @@ func (h *UsersHandler) SetIsActive(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(UserResponse{User: *user})
 }
 
+type SetPrimaryTeamRequest struct {
+	UserID   string `json:"user_id"`
+	TeamName string `json:"team_name"`
+}
+
+type UserTeamsResponse struct {
+	User  models.User `json:"user"`
+	Teams []string    `json:"teams"`
+}
+
+// SetPrimaryTeam makes one of the user's teams their primary one.
+func (h *UsersHandler) SetPrimaryTeam(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req SetPrimaryTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid user_id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.usersService.SetPrimaryTeam(r.Context(), userID, req.TeamName); err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	user, err := h.usersService.GetUser(r.Context(), userID)
+	if err != nil || user == nil {
+		respondError(w, "NOT_FOUND", "user not found", http.StatusNotFound)
+		return
+	}
+
+	teams, err := h.usersService.GetTeamNames(r.Context(), userID)
+	if err != nil {
+		respondError(w, "INTERNAL_ERROR", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(UserTeamsResponse{User: *user, Teams: teams})
+}
+
 type GetReviewResponse struct {
 	UserID       string               `json:"user_id"`
 	PullRequests []models.PullRequest `json:"pull_requests"`
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reviewer-service/internal/models"
+	srvUsers "reviewer-service/internal/services/users"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuthoredPRInfo struct {
+	PullRequestID   string       `json:"pull_request_id"`
+	PullRequestName string       `json:"pull_request_name"`
+	Status          string       `json:"status"`
+	CreatedAt       time.Time    `json:"created_at"`
+	MergedAt        *time.Time   `json:"merged_at"`
+	Labels          []string     `json:"labels"`
+	AgeSeconds      int64        `json:"age_seconds"`
+	Blocked         bool         `json:"blocked"`
+	Reviewers       []ReviewInfo `json:"reviewers"`
+}
+
+type ReviewInfo struct {
+	UserID     string     `json:"user_id"`
+	Verdict    string     `json:"verdict"`
+	AssignedAt time.Time  `json:"assigned_at"`
+	DecidedAt  *time.Time `json:"decided_at"`
+	AgeSeconds int64      `json:"age_seconds"`
+}
+
+type BlockingReviewerInfo struct {
+	UserID           string   `json:"user_id"`
+	PRs              int      `json:"prs"`
+	Pending          int      `json:"pending"`
+	ChangesRequested int      `json:"changes_requested"`
+	PullRequestIDs   []string `json:"pull_request_ids"`
+}
+
+type AuthoredSummary struct {
+	Open       int                    `json:"open"`
+	Merged     int                    `json:"merged"`
+	Blocked    int                    `json:"blocked"`
+	Unassigned int                    `json:"unassigned"`
+	BlockedOn  []BlockingReviewerInfo `json:"blocked_on"`
+}
+
+type GetAuthoredResponse struct {
+	UserID       string           `json:"user_id"`
+	PullRequests []AuthoredPRInfo `json:"pull_requests"`
+	Summary      AuthoredSummary  `json:"summary"`
+}
+
+// GetAuthored returns the PRs the user authored, newest first.
+func (h *UsersHandler) GetAuthored(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	userID, err := uuid.Parse(query.Get("user_id"))
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid user_id", http.StatusBadRequest)
+		return
+	}
+
+	authored, err := h.usersService.GetAuthoredPullRequests(r.Context(), userID, models.PullRequestStatus(query.Get("status")))
+	if err != nil {
+		if errors.Is(err, srvUsers.ErrInvalidFilter) {
+			respondError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+			return
+		}
+		respondError(w, "INTERNAL_ERROR", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if authored == nil {
+		respondError(w, "NOT_FOUND", "user not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newGetAuthoredResponse(userID.String(), authored, time.Now()))
+}
+
+func newGetAuthoredResponse(userID string, authored *models.AuthoredPullRequests, now time.Time) GetAuthoredResponse {
+	resp := GetAuthoredResponse{
+		UserID:       userID,
+		PullRequests: make([]AuthoredPRInfo, 0, len(authored.PullRequests)),
+		Summary: AuthoredSummary{
+			Open:       authored.Open,
+			Merged:     authored.Merged,
+			Blocked:    authored.Blocked,
+			Unassigned: authored.Unassigned,
+			BlockedOn:  make([]BlockingReviewerInfo, 0, len(authored.BlockedOn)),
+		},
+	}
+
+	for _, pr := range authored.PullRequests {
+		info := AuthoredPRInfo{
+			PullRequestID:   pr.ID,
+			PullRequestName: pr.Name,
+			Status:          string(pr.Status),
+			CreatedAt:       pr.CreatedAt,
+			MergedAt:        pr.MergedAt,
+			Labels:          pr.Labels,
+			AgeSeconds:      ageSeconds(pr.CreatedAt, pr.MergedAt, now),
+			Blocked:         pr.Blocked(),
+			Reviewers:       make([]ReviewInfo, 0, len(pr.Reviews)),
+		}
+		if info.Labels == nil {
+			info.Labels = []string{}
+		}
+		for _, review := range pr.Reviews {
+			info.Reviewers = append(info.Reviewers, ReviewInfo{
+				UserID:     review.ReviewerID,
+				Verdict:    string(review.Verdict),
+				AssignedAt: review.AssignedAt,
+				DecidedAt:  review.DecidedAt,
+				AgeSeconds: ageSeconds(review.AssignedAt, review.DecidedAt, now),
+			})
+		}
+		resp.PullRequests = append(resp.PullRequests, info)
+	}
+
+	for _, reviewer := range authored.BlockedOn {
+		resp.Summary.BlockedOn = append(resp.Summary.BlockedOn, BlockingReviewerInfo{
+			UserID:           reviewer.ReviewerID,
+			PRs:              len(reviewer.PRIDs),
+			Pending:          reviewer.Pending,
+			ChangesRequested: reviewer.ChangesRequested,
+			PullRequestIDs:   reviewer.PRIDs,
+		})
+	}
+	return resp
+}
+
+func ageSeconds(start time.Time, end *time.Time, now time.Time) int64 {
+	if end != nil {
+		now = *end
+	}
+	return int64(now.Sub(start) / time.Second)
+}
@@ -1,22 +1,57 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
-	"math/rand"
+	"errors"
 	"net/http"
 	"reviewer-service/internal/models"
+	srvBulk "reviewer-service/internal/services/bulk"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// BulkDeactivateRequest deactivates a whole team.
 type BulkDeactivateRequest struct {
-	TeamName string `json:"team_name"`
+	TeamName      string               `json:"team_name"`
+	Fallback      string               `json:"fallback"`
+	ApprovedBy    string               `json:"approved_by,omitempty"`
+	Async         bool                 `json:"async"`
+	DryRun        bool                 `json:"dry_run"`
+	PlanToken     string               `json:"plan_token"`
+	ReassignedPRs []PRReassignmentInfo `json:"reassigned_prs"`
+	UnresolvedPRs []UnresolvedPRInfo   `json:"unresolved_prs"`
+}
+
+type BulkDeactivateUsersRequest struct {
+	UserIDs    []string `json:"user_ids"`
+	Fallback   string   `json:"fallback"`
+	ApprovedBy string   `json:"approved_by,omitempty"`
+	Async      bool     `json:"async"`
 }
 
 type BulkDeactivateResponse struct {
+	OperationID      string               `json:"operation_id,omitempty"`
 	DeactivatedUsers []string             `json:"deactivated_users"`
 	ReassignedPRs    []PRReassignmentInfo `json:"reassigned_prs"`
+	UnresolvedPRs    []UnresolvedPRInfo   `json:"unresolved_prs"`
+	DryRun           bool                 `json:"dry_run"`
+	PlanToken        string               `json:"plan_token,omitempty"`
+	DurationMs       int64                `json:"duration_ms"`
+}
+
+type BulkReactivateRequest struct {
+	TeamName       string `json:"team_name"`
+	OperationID    string `json:"operation_id"`
+	RestoreReviews bool   `json:"restore_reviews"`
+	Async          bool   `json:"async"`
+}
+
+type BulkReactivateResponse struct {
+	OperationID      string               `json:"operation_id,omitempty"`
+	ReactivatedUsers []string             `json:"reactivated_users"`
+	RestoredPRs      []PRReassignmentInfo `json:"restored_prs"`
 	DurationMs       int64                `json:"duration_ms"`
 }
 
@@ -24,6 +59,14 @@ type PRReassignmentInfo struct {
 	PRID         string   `json:"pr_id"`
 	Replaced     []string `json:"replaced"`
 	NewReviewers []string `json:"new_reviewers"`
+	Removed      []string `json:"removed,omitempty"`
+	Fallback     string   `json:"fallback,omitempty"`
+}
+
+type UnresolvedPRInfo struct {
+	PRID      string   `json:"pr_id"`
+	Reviewers []string `json:"reviewers"`
+	Reason    string   `json:"reason"`
 }
 
 func (h *UsersHandler) BulkDeactivateTeam(w http.ResponseWriter, r *http.Request) {
@@ -45,139 +88,302 @@ func (h *UsersHandler) BulkDeactivateTeam(w http.ResponseWriter, r *http.Request
 		respondError(w, "NOT_FOUND", "team not found", http.StatusNotFound)
 		return
 	}
-	team := teamModel.Members
 
-	var activeUserIDs []string
-	for _, user := range team {
-		if user.IsActive {
-			activeUserIDs = append(activeUserIDs, user.ID)
-		}
+	if _, err := srvBulk.ParseFallback(req.Fallback); err != nil {
+		respondError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	if len(activeUserIDs) == 0 {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(BulkDeactivateResponse{
-			DeactivatedUsers: []string{},
-			ReassignedPRs:    []PRReassignmentInfo{},
-			DurationMs:       time.Since(startTime).Milliseconds(),
-		})
-		return
+	if !req.DryRun {
+		if err := h.bulkService.CheckTeamApproval(r.Context(), req.TeamName, req.ApprovedBy); err != nil {
+			respondBulkError(w, err)
+			return
+		}
 	}
 
-	inactiveUUIDs := make([]uuid.UUID, len(activeUserIDs))
-	for i, id := range activeUserIDs {
-		uid, err := uuid.Parse(id)
+	if req.Async {
+		job, err := h.jobsService.Submit(r.Context(), models.JobKindBulkDeactivateTeam, req)
 		if err != nil {
-			respondError(w, "INVALID_REQUEST", "Invalid user_id", http.StatusBadRequest)
+			respondError(w, "INTERNAL_ERROR", err.Error(), http.StatusInternalServerError)
 			return
 		}
-		inactiveUUIDs[i] = uid
+		respondJobAccepted(w, job)
+		return
 	}
 
-	openPRs, err := h.pullRequestsService.GetOpenPRsWithInactiveReviewers(r.Context(), activeUserIDs)
+	resp, err := h.runBulkDeactivateTeam(r.Context(), req, startTime, nil)
 	if err != nil {
-		respondError(w, "INTERNAL_ERROR", err.Error(), http.StatusInternalServerError)
+		respondBulkError(w, err)
 		return
 	}
 
-	var reassignments []models.ReviewerReassignment
-	var reassignedPRs []PRReassignmentInfo
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
 
-	for _, pr := range openPRs {
-		authorID, err := uuid.Parse(pr.AuthorID)
-		if err != nil {
-			continue
-		}
-		author, err := h.usersService.GetUser(r.Context(), authorID)
-		if err != nil || author == nil {
-			continue
+func (h *UsersHandler) BulkDeactivateUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	startTime := time.Now()
+
+	var req BulkDeactivateUsersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	for _, id := range req.UserIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			respondError(w, "INVALID_REQUEST", "Invalid user_id", http.StatusBadRequest)
+			return
 		}
+	}
+
+	if _, err := srvBulk.ParseFallback(req.Fallback); err != nil {
+		respondError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.bulkService.CheckUsersApproval(r.Context(), req.UserIDs, req.ApprovedBy); err != nil {
+		respondBulkError(w, err)
+		return
+	}
 
-		authorTeamModel, err := h.teamsService.GetTeam(r.Context(), author.TeamName)
+	if req.Async {
+		job, err := h.jobsService.Submit(r.Context(), models.JobKindBulkDeactivateUsers, req)
 		if err != nil {
-			continue
-		}
-		authorTeam := authorTeamModel.Members
-
-		var inactiveReviewers []string
-		for _, reviewerID := range pr.Reviewers {
-			for _, inactiveID := range activeUserIDs {
-				if reviewerID == inactiveID {
-					inactiveReviewers = append(inactiveReviewers, reviewerID)
-					break
-				}
-			}
+			respondError(w, "INTERNAL_ERROR", err.Error(), http.StatusInternalServerError)
+			return
 		}
+		respondJobAccepted(w, job)
+		return
+	}
 
-		if len(inactiveReviewers) == 0 {
-			continue
-		}
+	resp, err := h.runBulkDeactivateUsers(r.Context(), req, startTime, nil)
+	if err != nil {
+		respondBulkError(w, err)
+		return
+	}
 
-		excludeIDs := map[string]bool{
-			pr.AuthorID: true,
-		}
-		for _, reviewerID := range pr.Reviewers {
-			excludeIDs[reviewerID] = true
-		}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
 
-		var candidates []string
-		for _, member := range authorTeam {
-			if !excludeIDs[member.ID] && member.IsActive {
-				candidates = append(candidates, member.ID)
-			}
-		}
+func (h *UsersHandler) BulkReactivate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-		rand.Seed(time.Now().UnixNano())
-		rand.Shuffle(len(candidates), func(i, j int) {
-			candidates[i], candidates[j] = candidates[j], candidates[i]
-		})
+	startTime := time.Now()
 
-		var replaced []string
-		var newReviewers []string
-		for i, inactiveReviewerID := range inactiveReviewers {
-			if i >= len(candidates) {
-				break
-			}
-
-			oldUUID, _ := uuid.Parse(inactiveReviewerID)
-			newUUID, _ := uuid.Parse(candidates[i])
-
-			reassignments = append(reassignments, models.ReviewerReassignment{
-				PRID:          pr.ID,
-				OldReviewerID: oldUUID,
-				NewReviewerID: newUUID,
-			})
-			replaced = append(replaced, inactiveReviewerID)
-			newReviewers = append(newReviewers, candidates[i])
-		}
+	var req BulkReactivateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid request body", http.StatusBadRequest)
+		return
+	}
 
-		if len(replaced) > 0 {
-			reassignedPRs = append(reassignedPRs, PRReassignmentInfo{
-				PRID:         pr.ID,
-				Replaced:     replaced,
-				NewReviewers: newReviewers,
-			})
+	if req.OperationID != "" {
+		if _, err := uuid.Parse(req.OperationID); err != nil {
+			respondError(w, "INVALID_REQUEST", "Invalid operation_id", http.StatusBadRequest)
+			return
 		}
 	}
 
-	if len(reassignments) > 0 {
-		if err := h.pullRequestsService.BulkReassignReviewers(r.Context(), reassignments); err != nil {
-			respondError(w, "INTERNAL_ERROR", "Failed to reassign reviewers", http.StatusInternalServerError)
+	if req.Async {
+		job, err := h.jobsService.Submit(r.Context(), models.JobKindBulkReactivate, req)
+		if err != nil {
+			respondError(w, "INTERNAL_ERROR", err.Error(), http.StatusInternalServerError)
 			return
 		}
+		respondJobAccepted(w, job)
+		return
 	}
 
-	if err := h.usersService.SetTeamInactive(r.Context(), req.TeamName); err != nil {
-		respondError(w, "INTERNAL_ERROR", "Failed to deactivate users", http.StatusInternalServerError)
+	resp, err := h.runBulkReactivate(r.Context(), req, startTime)
+	if err != nil {
+		respondBulkError(w, err)
 		return
 	}
 
-	duration := time.Since(startTime).Milliseconds()
-
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(BulkDeactivateResponse{
-		DeactivatedUsers: activeUserIDs,
-		ReassignedPRs:    reassignedPRs,
-		DurationMs:       duration,
+	json.NewEncoder(w).Encode(resp)
+}
+
+// BulkReactivateJob runs an asynchronous /users/bulkReactivate request.
+func (h *UsersHandler) BulkReactivateJob(ctx context.Context, params []byte, _ func(done, total int)) (interface{}, error) {
+	var req BulkReactivateRequest
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, err
+	}
+	return h.runBulkReactivate(ctx, req, time.Now())
+}
+
+// BulkDeactivateTeamJob runs an asynchronous /users/bulkDeactivateTeam request.
+func (h *UsersHandler) BulkDeactivateTeamJob(ctx context.Context, params []byte, progress func(done, total int)) (interface{}, error) {
+	var req BulkDeactivateRequest
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, err
+	}
+	return h.runBulkDeactivateTeam(ctx, req, time.Now(), progress)
+}
+
+// BulkDeactivateUsersJob runs an asynchronous /users/bulkDeactivate request.
+func (h *UsersHandler) BulkDeactivateUsersJob(ctx context.Context, params []byte, progress func(done, total int)) (interface{}, error) {
+	var req BulkDeactivateUsersRequest
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, err
+	}
+	return h.runBulkDeactivateUsers(ctx, req, time.Now(), progress)
+}
+
+func (h *UsersHandler) runBulkDeactivateTeam(ctx context.Context, req BulkDeactivateRequest, startTime time.Time, progress func(done, total int)) (*BulkDeactivateResponse, error) {
+	fallback, err := srvBulk.ParseFallback(req.Fallback)
+	if err != nil {
+		return nil, err
+	}
+	opts := srvBulk.Options{Fallback: fallback, ApprovedBy: req.ApprovedBy, Progress: progress}
+
+	var result *models.BulkDeactivation
+	switch {
+	case req.DryRun:
+		result, err = h.bulkService.PlanTeamDeactivation(ctx, req.TeamName, opts)
+	case req.PlanToken != "":
+		result, err = h.bulkService.ApplyTeamPlan(ctx, req.TeamName, req.ApprovedBy, models.BulkDeactivation{
+			ReassignedPRs: fromPRReassignmentInfos(req.ReassignedPRs),
+			UnresolvedPRs: fromUnresolvedPRInfos(req.UnresolvedPRs),
+			PlanToken:     req.PlanToken,
+		})
+	default:
+		result, err = h.bulkService.DeactivateTeam(ctx, req.TeamName, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp := newBulkDeactivateResponse(result, startTime)
+	resp.DryRun = req.DryRun
+	return &resp, nil
+}
+
+func (h *UsersHandler) runBulkDeactivateUsers(ctx context.Context, req BulkDeactivateUsersRequest, startTime time.Time, progress func(done, total int)) (*BulkDeactivateResponse, error) {
+	fallback, err := srvBulk.ParseFallback(req.Fallback)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := h.bulkService.DeactivateUsers(ctx, req.UserIDs, srvBulk.Options{
+		Fallback:   fallback,
+		ApprovedBy: req.ApprovedBy,
+		Progress:   progress,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := newBulkDeactivateResponse(result, startTime)
+	return &resp, nil
+}
+
+func (h *UsersHandler) runBulkReactivate(ctx context.Context, req BulkReactivateRequest, startTime time.Time) (*BulkReactivateResponse, error) {
+	result, err := h.bulkService.Reactivate(ctx, srvBulk.ReactivateRequest{
+		TeamName:       req.TeamName,
+		OperationID:    req.OperationID,
+		RestoreReviews: req.RestoreReviews,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &BulkReactivateResponse{
+		OperationID:      result.OperationID,
+		ReactivatedUsers: result.ReactivatedUsers,
+		RestoredPRs:      toPRReassignmentInfos(result.RestoredPRs),
+		DurationMs:       time.Since(startTime).Milliseconds(),
+	}, nil
+}
+
+func respondBulkError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, srvBulk.ErrNoUsers), errors.Is(err, srvBulk.ErrInvalidPlan),
+		errors.Is(err, srvBulk.ErrNoScope), errors.Is(err, srvBulk.ErrNeedsOp):
+		respondError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+	case errors.Is(err, srvBulk.ErrUserNotFound), errors.Is(err, models.ErrOperationNotFound),
+		errors.Is(err, models.ErrTeamNotFound):
+		respondError(w, "NOT_FOUND", err.Error(), http.StatusNotFound)
+	case errors.Is(err, srvBulk.ErrApprovalRequired), errors.Is(err, srvBulk.ErrNotApprover):
+		respondError(w, "APPROVAL_REQUIRED", err.Error(), http.StatusForbidden)
+	case errors.Is(err, srvBulk.ErrPlanStale):
+		respondError(w, "PLAN_STALE", "team state changed since the plan was computed", http.StatusConflict)
+	case errors.Is(err, models.ErrUserErased):
+		respondError(w, "USER_ERASED", err.Error(), http.StatusConflict)
+	default:
+		respondError(w, "INTERNAL_ERROR", err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func newBulkDeactivateResponse(result *models.BulkDeactivation, startTime time.Time) BulkDeactivateResponse {
+	return BulkDeactivateResponse{
+		OperationID:      result.OperationID,
+		DeactivatedUsers: result.DeactivatedUsers,
+		ReassignedPRs:    toPRReassignmentInfos(result.ReassignedPRs),
+		UnresolvedPRs:    toUnresolvedPRInfos(result.UnresolvedPRs),
+		PlanToken:        result.PlanToken,
+		DurationMs:       time.Since(startTime).Milliseconds(),
+	}
+}
+
+func toPRReassignmentInfos(items []models.PRReassignment) []PRReassignmentInfo {
+	result := make([]PRReassignmentInfo, len(items))
+	for i, pr := range items {
+		result[i] = PRReassignmentInfo{
+			PRID:         pr.PRID,
+			Replaced:     pr.Replaced,
+			NewReviewers: pr.NewReviewers,
+			Removed:      pr.Removed,
+			Fallback:     pr.Fallback,
+		}
+	}
+	return result
+}
+
+func fromPRReassignmentInfos(items []PRReassignmentInfo) []models.PRReassignment {
+	result := make([]models.PRReassignment, len(items))
+	for i, item := range items {
+		result[i] = models.PRReassignment{
+			PRID:         item.PRID,
+			Replaced:     item.Replaced,
+			NewReviewers: item.NewReviewers,
+			Removed:      item.Removed,
+			Fallback:     item.Fallback,
+		}
+	}
+	return result
+}
+
+func toUnresolvedPRInfos(items []models.UnresolvedPR) []UnresolvedPRInfo {
+	result := make([]UnresolvedPRInfo, len(items))
+	for i, pr := range items {
+		result[i] = UnresolvedPRInfo{
+			PRID:      pr.PRID,
+			Reviewers: pr.Reviewers,
+			Reason:    pr.Reason,
+		}
+	}
+	return result
+}
+
+func fromUnresolvedPRInfos(items []UnresolvedPRInfo) []models.UnresolvedPR {
+	result := make([]models.UnresolvedPR, len(items))
+	for i, item := range items {
+		result[i] = models.UnresolvedPR{
+			PRID:      item.PRID,
+			Reviewers: item.Reviewers,
+			Reason:    item.Reason,
+		}
+	}
+	return result
 }
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reviewer-service/internal/models"
+	srvUsers "reviewer-service/internal/services/users"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+type UserProfileInfo struct {
+	UserID          string   `json:"user_id"`
+	Username        string   `json:"username"`
+	TeamName        string   `json:"team_name"`
+	Teams           []string `json:"teams"`
+	IsActive        bool     `json:"is_active"`
+	OpenReviews     int      `json:"open_reviews"`
+	AuthoredOpenPRs []string `json:"authored_open_prs"`
+}
+
+type UserProfileResponse struct {
+	User UserProfileInfo `json:"user"`
+}
+
+type UserListResponse struct {
+	Users  []UserProfileInfo `json:"users"`
+	Total  int               `json:"total"`
+	Limit  int               `json:"limit"`
+	Offset int               `json:"offset"`
+}
+
+func (h *UsersHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, err := uuid.Parse(r.URL.Query().Get("user_id"))
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid user_id", http.StatusBadRequest)
+		return
+	}
+
+	profile, err := h.usersService.GetProfile(r.Context(), userID)
+	if err != nil {
+		respondError(w, "INTERNAL_ERROR", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if profile == nil {
+		respondError(w, "NOT_FOUND", "user not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(UserProfileResponse{User: newUserProfileInfo(profile)})
+}
+
+// ListUsers returns a page of users ordered by username.
+func (h *UsersHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := models.UserFilter{
+		TeamName:   query.Get("team_name"),
+		NamePrefix: query.Get("name_prefix"),
+		Role:       models.TeamRole(query.Get("role")),
+	}
+
+	if value := query.Get("is_active"); value != "" {
+		isActive, err := strconv.ParseBool(value)
+		if err != nil {
+			respondError(w, "INVALID_REQUEST", "Invalid is_active", http.StatusBadRequest)
+			return
+		}
+		filter.IsActive = &isActive
+	}
+
+	var err error
+	if value := query.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil {
+			respondError(w, "INVALID_REQUEST", "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("offset"); value != "" {
+		if filter.Offset, err = strconv.Atoi(value); err != nil {
+			respondError(w, "INVALID_REQUEST", "Invalid offset", http.StatusBadRequest)
+			return
+		}
+	}
+
+	profiles, total, err := h.usersService.ListUsers(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, srvUsers.ErrInvalidFilter) {
+			respondError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+			return
+		}
+		respondError(w, "INTERNAL_ERROR", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := UserListResponse{
+		Users:  make([]UserProfileInfo, 0, len(profiles)),
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}
+	if resp.Limit == 0 {
+		resp.Limit = srvUsers.DefaultPageSize
+	}
+	for _, p := range profiles {
+		resp.Users = append(resp.Users, newUserProfileInfo(p))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func newUserProfileInfo(p *models.UserProfile) UserProfileInfo {
+	info := UserProfileInfo{
+		UserID:          p.ID,
+		Username:        p.Username,
+		TeamName:        p.TeamName,
+		Teams:           p.Teams,
+		IsActive:        p.IsActive,
+		OpenReviews:     p.OpenReviews,
+		AuthoredOpenPRs: p.AuthoredOpenPRs,
+	}
+	if info.Teams == nil {
+		info.Teams = []string{}
+	}
+	if info.AuthoredOpenPRs == nil {
+		info.AuthoredOpenPRs = []string{}
+	}
+	return info
+}
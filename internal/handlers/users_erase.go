@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reviewer-service/internal/models"
+	srvBulk "reviewer-service/internal/services/bulk"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EraseUserRequest erases a departing user.
+type EraseUserRequest struct {
+	UserID     string `json:"user_id"`
+	Reason     string `json:"reason"`
+	Fallback   string `json:"fallback"`
+	ApprovedBy string `json:"approved_by,omitempty"`
+}
+
+type ErasureInfo struct {
+	ErasureID       string    `json:"erasure_id"`
+	UserID          string    `json:"user_id"`
+	Pseudonym       string    `json:"pseudonym"`
+	ApprovedBy      string    `json:"approved_by,omitempty"`
+	Reason          string    `json:"reason"`
+	ReassignedCount int       `json:"reassigned_count"`
+	UnresolvedCount int       `json:"unresolved_count"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type EraseUserResponse struct {
+	Erasure       ErasureInfo          `json:"erasure"`
+	ReassignedPRs []PRReassignmentInfo `json:"reassigned_prs"`
+	UnresolvedPRs []UnresolvedPRInfo   `json:"unresolved_prs"`
+}
+
+type ErasuresResponse struct {
+	Erasures []ErasureInfo `json:"erasures"`
+}
+
+// EraseUser pseudonymises and deactivates the user and hands their reviews over.
+func (h *UsersHandler) EraseUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req EraseUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := uuid.Parse(req.UserID); err != nil {
+		respondError(w, "INVALID_REQUEST", "Invalid user_id", http.StatusBadRequest)
+		return
+	}
+
+	fallback, err := srvBulk.ParseFallback(req.Fallback)
+	if err != nil {
+		respondError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.bulkService.CheckUsersApproval(r.Context(), []string{req.UserID}, req.ApprovedBy); err != nil {
+		respondBulkError(w, err)
+		return
+	}
+
+	erasure, handover, err := h.bulkService.EraseUser(r.Context(), req.UserID, req.Reason, srvBulk.Options{
+		Fallback:   fallback,
+		ApprovedBy: req.ApprovedBy,
+	})
+	if err != nil {
+		respondBulkError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(EraseUserResponse{
+		Erasure:       newErasureInfo(*erasure),
+		ReassignedPRs: toPRReassignmentInfos(handover.ReassignedPRs),
+		UnresolvedPRs: toUnresolvedPRInfos(handover.UnresolvedPRs),
+	})
+}
+
+func (h *UsersHandler) ListErasures(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := r.URL.Query().Get("user_id")
+	if userID != "" {
+		if _, err := uuid.Parse(userID); err != nil {
+			respondError(w, "INVALID_REQUEST", "Invalid user_id", http.StatusBadRequest)
+			return
+		}
+	}
+
+	erasures, err := h.bulkService.ListErasures(r.Context(), userID)
+	if err != nil {
+		respondError(w, "INTERNAL_ERROR", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := ErasuresResponse{Erasures: make([]ErasureInfo, 0, len(erasures))}
+	for _, e := range erasures {
+		resp.Erasures = append(resp.Erasures, newErasureInfo(e))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func newErasureInfo(e models.UserErasure) ErasureInfo {
+	return ErasureInfo{
+		ErasureID:       e.ID,
+		UserID:          e.UserID,
+		Pseudonym:       e.Pseudonym,
+		ApprovedBy:      e.ApprovedBy,
+		Reason:          e.Reason,
+		ReassignedCount: e.ReassignedPRs,
+		UnresolvedCount: e.UnresolvedPRs,
+		CreatedAt:       e.CreatedAt,
+	}
+}
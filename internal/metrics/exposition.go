@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Label is one name="value" pair of a sample.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is one value of a metric family. Suffix is appended to the family
+// name, as in the _bucket, _sum and _count series of a histogram.
+type Sample struct {
+	Suffix string
+	Labels []Label
+	Value  float64
+}
+
+// WriteFamily writes the HELP and TYPE lines of a metric family and its samples.
+func WriteFamily(w io.Writer, name, kind, help string, samples []Sample) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, helpEscaper.Replace(help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+	for _, sample := range samples {
+		io.WriteString(w, name+sample.Suffix)
+		if len(sample.Labels) > 0 {
+			parts := make([]string, 0, len(sample.Labels))
+			for _, label := range sample.Labels {
+				parts = append(parts, label.Name+`="`+labelEscaper.Replace(label.Value)+`"`)
+			}
+			io.WriteString(w, "{"+strings.Join(parts, ",")+"}")
+		}
+		io.WriteString(w, " "+formatValue(sample.Value)+"\n")
+	}
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
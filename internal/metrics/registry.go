@@ -0,0 +1,171 @@
+package metrics
+
+import (
+	"database/sql"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var DurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Registry collects HTTP, database pool and reviewer assignment metrics.
+type Registry struct {
+	db *sql.DB
+
+	mu       sync.Mutex
+	requests map[requestKey]*histogram
+
+	assignments     atomic.Int64
+	reassignments   atomic.Int64
+	noCandidates    atomic.Int64
+	bulkOperations  atomic.Int64
+	bulkDeactivated atomic.Int64
+}
+
+type requestKey struct {
+	route  string
+	status int
+}
+
+type histogram struct {
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+func New(db *sql.DB) *Registry {
+	return &Registry{
+		db:       db,
+		requests: map[requestKey]*histogram{},
+	}
+}
+
+// AddAssignments counts reviewers assigned to new pull requests.
+func (r *Registry) AddAssignments(n int) { r.assignments.Add(int64(n)) }
+
+// AddReassignments counts reviews moved from one reviewer to another.
+func (r *Registry) AddReassignments(n int) { r.reassignments.Add(int64(n)) }
+
+// AddNoCandidates counts reviews nobody could take over.
+func (r *Registry) AddNoCandidates(n int) { r.noCandidates.Add(int64(n)) }
+
+// AddBulkDeactivation counts one bulk deactivation of the given users.
+func (r *Registry) AddBulkDeactivation(users int) {
+	r.bulkOperations.Add(1)
+	r.bulkDeactivated.Add(int64(users))
+}
+
+// Instrument counts and times every request under the pattern it matched.
+// Unmatched requests share one route to keep the number of series bounded.
+func (r *Registry) Instrument(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, route := mux.Handler(req)
+		if route == "" {
+			route = "unmatched"
+		}
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		mux.ServeHTTP(rec, req)
+		r.observe(requestKey{route: route, status: rec.status}, time.Since(start).Seconds())
+	})
+}
+
+func (r *Registry) observe(key requestKey, seconds float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	h, ok := r.requests[key]
+	if !ok {
+		h = &histogram{buckets: make([]uint64, len(DurationBuckets))}
+		r.requests[key] = h
+	}
+	for i, bound := range DurationBuckets {
+		if seconds <= bound {
+			h.buckets[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// Write writes every metric of the registry in the text exposition format.
+func (r *Registry) Write(w io.Writer) {
+	r.writeRequests(w)
+
+	if r.db != nil {
+		stats := r.db.Stats()
+		writeGauge(w, "reviewer_service_db_open_connections", "Open database connections, in use and idle.", float64(stats.OpenConnections))
+		writeGauge(w, "reviewer_service_db_in_use_connections", "Database connections currently in use.", float64(stats.InUse))
+		writeGauge(w, "reviewer_service_db_idle_connections", "Idle database connections.", float64(stats.Idle))
+		writeGauge(w, "reviewer_service_db_max_open_connections", "Maximum number of open database connections.", float64(stats.MaxOpenConnections))
+		writeCounter(w, "reviewer_service_db_wait_count_total", "Connections waited for.", float64(stats.WaitCount))
+		writeCounter(w, "reviewer_service_db_wait_duration_seconds_total", "Time spent waiting for a connection.", stats.WaitDuration.Seconds())
+	}
+
+	writeCounter(w, "reviewer_service_assignments_made_total", "Reviewers assigned to new pull requests.", float64(r.assignments.Load()))
+	writeCounter(w, "reviewer_service_reassignments_total", "Reviews moved to another reviewer.", float64(r.reassignments.Load()))
+	writeCounter(w, "reviewer_service_no_candidate_total", "Reviews no replacement reviewer was found for.", float64(r.noCandidates.Load()))
+	writeCounter(w, "reviewer_service_bulk_deactivations_total", "Bulk deactivations applied.", float64(r.bulkOperations.Load()))
+	writeCounter(w, "reviewer_service_bulk_deactivated_users_total", "Users deactivated by bulk deactivations.", float64(r.bulkDeactivated.Load()))
+}
+
+func (r *Registry) writeRequests(w io.Writer) {
+	r.mu.Lock()
+	keys := make([]requestKey, 0, len(r.requests))
+	for key := range r.requests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		return keys[i].status < keys[j].status
+	})
+
+	var requests, durations []Sample
+	for _, key := range keys {
+		h := r.requests[key]
+		labels := []Label{{Name: "route", Value: key.route}, {Name: "status", Value: strconv.Itoa(key.status)}}
+		requests = append(requests, Sample{Labels: labels, Value: float64(h.count)})
+		for i, bound := range DurationBuckets {
+			durations = append(durations, Sample{
+				Suffix: "_bucket",
+				Labels: append(labels[:2:2], Label{Name: "le", Value: formatValue(bound)}),
+				Value:  float64(h.buckets[i]),
+			})
+		}
+		durations = append(durations,
+			Sample{Suffix: "_bucket", Labels: append(labels[:2:2], Label{Name: "le", Value: "+Inf"}), Value: float64(h.count)},
+			Sample{Suffix: "_sum", Labels: labels, Value: h.sum},
+			Sample{Suffix: "_count", Labels: labels, Value: float64(h.count)},
+		)
+	}
+	r.mu.Unlock()
+
+	WriteFamily(w, "reviewer_service_http_requests_total", "counter", "HTTP requests by route and status.", requests)
+	WriteFamily(w, "reviewer_service_http_request_duration_seconds", "histogram", "HTTP request latency by route and status.", durations)
+}
+
+func writeGauge(w io.Writer, name, help string, value float64) {
+	WriteFamily(w, name, "gauge", help, []Sample{{Value: value}})
+}
+
+func writeCounter(w io.Writer, name, help string, value float64) {
+	WriteFamily(w, name, "counter", help, []Sample{{Value: value}})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
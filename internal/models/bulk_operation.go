@@ -0,0 +1,31 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrOperationNotFound = errors.New("bulk operation not found")
+
+const (
+	BulkOperationDeactivateTeam  = "DEACTIVATE_TEAM"
+	BulkOperationDeactivateUsers = "DEACTIVATE_USERS"
+)
+
+// BulkOperation records a bulk deactivation so that it can be reverted.
+type BulkOperation struct {
+	ID            string                 `db:"operation_id"`
+	Kind          string                 `db:"kind"`
+	TeamName      string                 `db:"team_name"`
+	ApprovedBy    string                 `db:"approved_by"`
+	UserIDs       []string               `db:"-"`
+	Reassignments []ReviewerReassignment `db:"-"`
+	CreatedAt     time.Time              `db:"created_at"`
+	ReactivatedAt *time.Time             `db:"reactivated_at"`
+}
+
+type BulkReactivation struct {
+	OperationID      string
+	ReactivatedUsers []string
+	RestoredPRs      []PRReassignment
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrJobNotFound = errors.New("job not found")
+
+type JobStatus string
+
+const (
+	JobStatusPending   JobStatus = "PENDING"
+	JobStatusRunning   JobStatus = "RUNNING"
+	JobStatusSucceeded JobStatus = "SUCCEEDED"
+	JobStatusFailed    JobStatus = "FAILED"
+	JobStatusCancelled JobStatus = "CANCELLED"
+)
+
+const (
+	JobKindBulkDeactivateTeam  = "bulk_deactivate_team"
+	JobKindBulkDeactivateUsers = "bulk_deactivate_users"
+	JobKindBulkReactivate      = "bulk_reactivate"
+)
+
+type Job struct {
+	ID         string     `db:"job_id"`
+	Kind       string     `db:"kind"`
+	Status     JobStatus  `db:"status"`
+	Params     []byte     `db:"params"`
+	Progress   int        `db:"progress"`
+	Total      int        `db:"total"`
+	Result     []byte     `db:"result"`
+	Error      string     `db:"error"`
+	CreatedAt  time.Time  `db:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at"`
+	FinishedAt *time.Time `db:"finished_at"`
+}
+
+func (j *Job) Finished() bool {
+	return j.Status == JobStatusSucceeded || j.Status == JobStatusFailed || j.Status == JobStatusCancelled
+}
@@ -0,0 +1,10 @@
+package models
+
+// MembershipChange describes a user leaving a team; ToTeam is empty on removal.
+type MembershipChange struct {
+	UserID        string
+	FromTeam      string
+	ToTeam        string
+	ReassignedPRs []PRReassignment
+	UnresolvedPRs []UnresolvedPR
+}
@@ -16,5 +16,67 @@ type PullRequest struct {
 	Status    PullRequestStatus `db:"status"`
 	CreatedAt time.Time         `db:"created_at"`
 	MergedAt  *time.Time        `db:"merged_at"`
+	Labels    []string          `db:"labels"`
 	Reviewers []string          `db:"-"`
 }
+
+type ReviewVerdict string
+
+const (
+	ReviewVerdictPending          ReviewVerdict = "PENDING"
+	ReviewVerdictApproved         ReviewVerdict = "APPROVED"
+	ReviewVerdictChangesRequested ReviewVerdict = "CHANGES_REQUESTED"
+)
+
+// Review is a reviewer's slot on a PR. A reassigned slot starts over as pending.
+type Review struct {
+	ReviewerID string        `db:"reviewer_id"`
+	Verdict    ReviewVerdict `db:"verdict"`
+	AssignedAt time.Time     `db:"assigned_at"`
+	DecidedAt  *time.Time    `db:"decided_at"`
+}
+
+// Blocking reports whether the review keeps an open PR from being merged.
+func (r Review) Blocking() bool {
+	return r.Verdict != ReviewVerdictApproved
+}
+
+// AuthoredPullRequest is a PR with the state of each of its reviews.
+type AuthoredPullRequest struct {
+	PullRequest
+	Reviews []Review
+}
+
+// Blocked reports whether the open PR is still waiting on a reviewer.
+func (pr AuthoredPullRequest) Blocked() bool {
+	if pr.Status != PullRequestStatusOpen {
+		return false
+	}
+	if len(pr.Reviews) == 0 {
+		return true
+	}
+	for _, review := range pr.Reviews {
+		if review.Blocking() {
+			return true
+		}
+	}
+	return false
+}
+
+// BlockingReviewer is a reviewer that open PRs of one author wait on.
+type BlockingReviewer struct {
+	ReviewerID       string
+	Pending          int
+	ChangesRequested int
+	PRIDs            []string
+}
+
+// AuthoredPullRequests is what a user authored and where it is stuck.
+type AuthoredPullRequests struct {
+	PullRequests []AuthoredPullRequest
+	Open         int
+	Merged       int
+	Blocked      int
+	Unassigned   int
+	BlockedOn    []BlockingReviewer
+}
@@ -2,9 +2,42 @@ package models
 
 import "github.com/google/uuid"
 
+// ReviewerReassignment replaces OldReviewerID with NewReviewerID on a PR. A
+// uuid.Nil NewReviewerID only removes, a uuid.Nil OldReviewerID only adds.
 type ReviewerReassignment struct {
-	PRID         string
+	PRID          string
 	OldReviewerID uuid.UUID
 	NewReviewerID uuid.UUID
 }
 
+type PRReassignment struct {
+	PRID         string
+	Replaced     []string
+	NewReviewers []string
+	Removed      []string
+	Fallback     string
+}
+
+type BulkDeactivation struct {
+	OperationID      string
+	DeactivatedUsers []string
+	ReassignedPRs    []PRReassignment
+	UnresolvedPRs    []UnresolvedPR
+	PlanToken        string
+}
+
+const (
+	UnresolvedReasonNoCandidate    = "NO_CANDIDATE"
+	UnresolvedReasonNoLead         = "NO_LEAD"
+	UnresolvedReasonAuthorNotFound = "AUTHOR_NOT_FOUND"
+	// UnresolvedReasonOutsideTeam marks PRs whose author is not in the team
+	// the reviewer leaves; the reviewer stays on them.
+	UnresolvedReasonOutsideTeam = "AUTHOR_OUTSIDE_TEAM"
+)
+
+// UnresolvedPR is an open PR whose deactivated reviewers could not be replaced.
+type UnresolvedPR struct {
+	PRID      string
+	Reviewers []string
+	Reason    string
+}
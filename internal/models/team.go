@@ -1,10 +1,71 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTeamNotFound  = errors.New("team not found")
+	ErrTeamExists    = errors.New("team_name already exists")
+	ErrNotTeamMember = errors.New("user is not a member of the team")
+	ErrTeamArchived  = errors.New("team is archived")
+	ErrTeamCycle     = errors.New("team cannot be nested under itself")
+	ErrTeamChanged   = errors.New("team members changed concurrently")
+)
+
+// TeamRole is the role of a user within one team.
+type TeamRole string
+
+const (
+	// TeamRoleLead members also approve bulk operations and take escalations.
+	TeamRoleLead TeamRole = "lead"
+	// TeamRoleMember is the default role.
+	TeamRoleMember TeamRole = "member"
+	// TeamRoleObserver members see the team but are never auto-assigned.
+	TeamRoleObserver TeamRole = "observer"
+)
 
 type Team struct {
-	Name    string `db:"team_name"`
-	Members []User `db:"-"`
+	ID               string     `db:"team_id"`
+	Name             string     `db:"team_name"`
+	ParentID         string     `db:"parent_team_id"`
+	LeadReviewLabels []string   `db:"lead_review_labels"`
+	ArchivedAt       *time.Time `db:"archived_at"`
+	Members          []User     `db:"-"`
+	SubTeams         []Team     `db:"-"`
+}
+
+// Archived reports whether the team was archived.
+func (t *Team) Archived() bool {
+	return t.ArchivedAt != nil
+}
+
+// Reviewers returns the members that may be auto-assigned.
+func (t *Team) Reviewers() []User {
+	if t.Archived() {
+		return nil
+	}
+	var reviewers []User
+	for _, m := range t.Members {
+		if m.Role != TeamRoleObserver {
+			reviewers = append(reviewers, m)
+		}
+	}
+	return reviewers
+}
+
+func (t *Team) RequiresLeadReview(labels []string) bool {
+	for _, label := range labels {
+		for _, l := range t.LeadReviewLabels {
+			if label == l {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 type MemberData struct {
@@ -12,3 +73,14 @@ type MemberData struct {
 	Username string
 	IsActive bool
 }
+
+// TeamDeletion reports a hard-deleted team.
+type TeamDeletion struct {
+	TeamName      string
+	TargetTeam    string
+	DetachedUsers []string
+	// MovedPRs are open PRs whose authors were moved to TargetTeam.
+	MovedPRs      []string
+	ReassignedPRs []PRReassignment
+	UnresolvedPRs []UnresolvedPR
+}
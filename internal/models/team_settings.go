@@ -0,0 +1,67 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrSettingsVersionConflict = errors.New("team settings were changed by someone else")
+
+// MaxReviewers caps how many reviewers a PR can get.
+const MaxReviewers = 5
+
+// SelectionStrategy decides the order in which candidates are picked.
+type SelectionStrategy string
+
+const (
+	// SelectionRandom picks candidates in random order.
+	SelectionRandom SelectionStrategy = "random"
+	// SelectionLeastLoaded prefers candidates with the fewest open reviews.
+	SelectionLeastLoaded SelectionStrategy = "least_loaded"
+)
+
+// ApprovalPolicy decides whether bulk operations on a team need a lead's approval.
+type ApprovalPolicy string
+
+const (
+	ApprovalNone ApprovalPolicy = "none"
+	// ApprovalLeadIfPresent requires a lead's approval when the team has an active lead.
+	ApprovalLeadIfPresent ApprovalPolicy = "lead_if_present"
+	// ApprovalLeadRequired makes a team without active leads impossible to bulk deactivate.
+	ApprovalLeadRequired ApprovalPolicy = "lead_required"
+)
+
+// TeamSettings is the configuration document of a team. It is stored as JSON,
+// hence the json tags.
+type TeamSettings struct {
+	ReviewerCount     int               `json:"reviewer_count"`
+	SelectionStrategy SelectionStrategy `json:"selection_strategy"`
+	ApprovalPolicy    ApprovalPolicy    `json:"approval_policy"`
+	SLA               SLAThresholds     `json:"sla"`
+	// FallbackTeamIDs are asked in order when the team has no free reviewer.
+	FallbackTeamIDs []string `json:"fallback_team_ids"`
+}
+
+// SLAThresholds are review deadlines in hours; zero disables a threshold.
+type SLAThresholds struct {
+	FirstReviewHours int `json:"first_review_hours"`
+	MergeHours       int `json:"merge_hours"`
+}
+
+// DefaultTeamSettings is what a team uses until its settings are saved.
+func DefaultTeamSettings() TeamSettings {
+	return TeamSettings{
+		ReviewerCount:     2,
+		SelectionStrategy: SelectionRandom,
+		ApprovalPolicy:    ApprovalLeadIfPresent,
+		FallbackTeamIDs:   []string{},
+	}
+}
+
+// TeamSettingsVersion is one saved revision of a team's settings.
+type TeamSettingsVersion struct {
+	TeamID    string       `db:"team_id"`
+	Version   int          `db:"version"`
+	Settings  TeamSettings `db:"settings"`
+	CreatedAt time.Time    `db:"created_at"`
+}
@@ -0,0 +1,53 @@
+package models
+
+// TeamManifest is the desired state of the teams it lists.
+type TeamManifest struct {
+	Teams []ManifestTeam `json:"teams" yaml:"teams"`
+}
+
+// ManifestTeam lists every member the team should have.
+type ManifestTeam struct {
+	Name    string           `json:"name" yaml:"name"`
+	Parent  string           `json:"parent,omitempty" yaml:"parent,omitempty"`
+	Members []ManifestMember `json:"members" yaml:"members"`
+}
+
+// ManifestMember is an active member of a team; Role defaults to member.
+type ManifestMember struct {
+	UserID   string   `json:"user_id" yaml:"user_id"`
+	Username string   `json:"username" yaml:"username"`
+	Role     TeamRole `json:"role,omitempty" yaml:"role,omitempty"`
+}
+
+type TeamSyncActionKind string
+
+const (
+	TeamSyncCreateTeam     TeamSyncActionKind = "create_team"
+	TeamSyncUpdateTeam     TeamSyncActionKind = "update_team"
+	TeamSyncCreateUser     TeamSyncActionKind = "create_user"
+	TeamSyncUpdateUser     TeamSyncActionKind = "update_user"
+	TeamSyncAddMember      TeamSyncActionKind = "add_member"
+	TeamSyncSetRole        TeamSyncActionKind = "set_role"
+	TeamSyncMoveMember     TeamSyncActionKind = "move_member"
+	TeamSyncRemoveMember   TeamSyncActionKind = "remove_member"
+	TeamSyncDeactivateUser TeamSyncActionKind = "deactivate_user"
+)
+
+// TeamSyncAction is one step of a team sync.
+type TeamSyncAction struct {
+	Kind       TeamSyncActionKind
+	TeamName   string
+	ParentTeam string
+	UserID     string
+	Username   string
+	FromTeam   string
+	Role       TeamRole
+}
+
+// TeamSync is the plan, or the result, of applying a manifest.
+type TeamSync struct {
+	Actions       []TeamSyncAction
+	ReassignedPRs []PRReassignment
+	UnresolvedPRs []UnresolvedPR
+	PlanToken     string
+}
@@ -5,4 +5,24 @@ type User struct {
 	Username string `db:"username"`
 	TeamName string `db:"team_name"`
 	IsActive bool   `db:"is_active"`
+	// Role is empty when the user is not loaded as part of a team.
+	Role TeamRole `db:"role"`
+}
+
+// UserFilter selects users for the user directory.
+type UserFilter struct {
+	TeamName   string
+	IsActive   *bool
+	NamePrefix string
+	Role       TeamRole
+	Limit      int
+	Offset     int
+}
+
+// UserProfile is a user with all of their teams, primary team first.
+type UserProfile struct {
+	User
+	Teams           []string
+	OpenReviews     int
+	AuthoredOpenPRs []string
 }
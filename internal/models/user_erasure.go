@@ -0,0 +1,20 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrUserErased = errors.New("user is already erased")
+
+// UserErasure is the audit record of an erased user. It holds no personal data.
+type UserErasure struct {
+	ID            string    `db:"erasure_id"`
+	UserID        string    `db:"user_id"`
+	Pseudonym     string    `db:"pseudonym"`
+	ApprovedBy    string    `db:"approved_by"`
+	Reason        string    `db:"reason"`
+	ReassignedPRs int       `db:"reassigned_prs"`
+	UnresolvedPRs int       `db:"unresolved_prs"`
+	CreatedAt     time.Time `db:"created_at"`
+}
@@ -3,10 +3,29 @@ package teams
 import (
 	"context"
 	"reviewer-service/internal/models"
+
+	"github.com/google/uuid"
 )
 
 type TeamRepository interface {
 	CreateTeam(ctx context.Context, team models.Team) error
 	GetTeamByName(ctx context.Context, name string) (*models.Team, error)
+	GetTeamByID(ctx context.Context, id uuid.UUID) (*models.Team, error)
+	GetAllTeams(ctx context.Context) ([]models.Team, error)
+	ImportTeams(ctx context.Context, teams []models.Team) error
+	RenameTeam(ctx context.Context, id uuid.UUID, name string) error
+	SetParent(ctx context.Context, id, parentID uuid.UUID) error
+	GetChildTeamIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+	GetSubtreeTeamIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+	SetRole(ctx context.Context, teamName string, userID uuid.UUID, role models.TeamRole) error
+	SetLeadReviewLabels(ctx context.Context, teamName string, labels []string) error
+	GetEscalationLeads(ctx context.Context, id uuid.UUID) ([]*models.User, error)
 	AddUsersToTeam(ctx context.Context, teamName string, users []models.User) error
+	RemoveUserFromTeam(ctx context.Context, teamName string, userID uuid.UUID, deactivate bool, reassignments []models.ReviewerReassignment) error
+	MoveUserToTeam(ctx context.Context, userID uuid.UUID, fromTeam, toTeam string, reassignments []models.ReviewerReassignment) error
+	ArchiveTeam(ctx context.Context, teamName string, op models.BulkOperation, reassignments []models.ReviewerReassignment) error
+	GetExclusiveMembers(ctx context.Context, teamName string) ([]uuid.UUID, error)
+	GetOpenPullRequestIDs(ctx context.Context, teamName string) ([]string, error)
+	DeleteTeam(ctx context.Context, teamName, targetTeam string, reassignments []models.ReviewerReassignment) ([]string, error)
+	SyncTeams(ctx context.Context, actions []models.TeamSyncAction, reassignments []models.ReviewerReassignment) error
 }
@@ -10,8 +10,22 @@ import (
 type Repository interface {
 	CreateUser(ctx context.Context, user models.User) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error)
+	GetByUsernames(ctx context.Context, usernames []string) ([]*models.User, error)
+	GetProfile(ctx context.Context, id uuid.UUID) (*models.UserProfile, error)
+	ListUsers(ctx context.Context, filter models.UserFilter) ([]*models.UserProfile, error)
+	CountUsers(ctx context.Context, filter models.UserFilter) (int, error)
 	SetIsActive(ctx context.Context, id uuid.UUID, isActive bool) error
 	SetTeamInactive(ctx context.Context, teamName string) error
+	DeactivateUsers(ctx context.Context, op models.BulkOperation, ids []uuid.UUID, reassignments []models.ReviewerReassignment, check func(openPRs []models.PullRequest) error) error
+	ReactivateUsers(ctx context.Context, operationID uuid.UUID, ids []uuid.UUID, restorations []models.ReviewerReassignment) error
+	EraseUser(ctx context.Context, erasure *models.UserErasure, reassignments []models.ReviewerReassignment) error
+	GetUserErasures(ctx context.Context, userID uuid.NullUUID) ([]models.UserErasure, error)
 	GetActiveByTeam(ctx context.Context, teamName string) ([]*models.User, error)
+	GetAllActive(ctx context.Context) ([]*models.User, error)
+	GetActiveInTeams(ctx context.Context, teamIDs []uuid.UUID) ([]*models.User, error)
+	GetTeamNames(ctx context.Context, id uuid.UUID) ([]string, error)
+	SetPrimaryTeam(ctx context.Context, id uuid.UUID, teamName string) error
 	GetAssignedPullRequests(ctx context.Context, userID uuid.UUID) ([]*models.PullRequest, error)
+	GetAuthoredPullRequests(ctx context.Context, authorID uuid.UUID, status models.PullRequestStatus) ([]models.AuthoredPullRequest, error)
 }
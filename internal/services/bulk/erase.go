@@ -0,0 +1,68 @@
+package bulk
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reviewer-service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Pseudonym is derived from the user id only, so it is stable per user.
+func Pseudonym(userID uuid.UUID) string {
+	sum := sha256.Sum256(userID[:])
+	return "erased-" + hex.EncodeToString(sum[:6])
+}
+
+// EraseUser pseudonymises the user and hands their open reviews over.
+func (s *Service) EraseUser(ctx context.Context, userID, reason string, opts Options) (*models.UserErasure, *models.BulkDeactivation, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid user_id %s: %w", userID, err)
+	}
+
+	users, err := s.usersRepo.GetByIDs(ctx, []uuid.UUID{id})
+	if err != nil {
+		return nil, nil, fmt.Errorf("get users: %w", err)
+	}
+	if len(users) == 0 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUserNotFound, id)
+	}
+
+	plan, reassignments, err := s.buildPlan(ctx, []uuid.UUID{id}, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	erasure := &models.UserErasure{
+		ID:            uuid.New().String(),
+		UserID:        id.String(),
+		Pseudonym:     Pseudonym(id),
+		ApprovedBy:    opts.ApprovedBy,
+		Reason:        reason,
+		ReassignedPRs: len(plan.ReassignedPRs),
+		UnresolvedPRs: len(plan.UnresolvedPRs),
+	}
+	if err := s.usersRepo.EraseUser(ctx, erasure, reassignments); err != nil {
+		return nil, nil, err
+	}
+	s.recordHandover(reassignments, plan.UnresolvedPRs)
+
+	plan.DeactivatedUsers = []string{erasure.UserID}
+	plan.PlanToken = ""
+	return erasure, plan, nil
+}
+
+func (s *Service) ListErasures(ctx context.Context, userID string) ([]models.UserErasure, error) {
+	var id uuid.NullUUID
+	if userID != "" {
+		parsed, err := uuid.Parse(userID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user_id %s: %w", userID, err)
+		}
+		id = uuid.NullUUID{UUID: parsed, Valid: true}
+	}
+	return s.usersRepo.GetUserErasures(ctx, id)
+}
@@ -0,0 +1,1093 @@
+package bulk
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reviewer-service/internal/metrics"
+	"reviewer-service/internal/models"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNoUsers      = errors.New("user_ids must not be empty")
+	ErrUserNotFound = errors.New("user not found")
+	ErrPlanStale    = errors.New("plan is stale")
+	ErrInvalidPlan  = errors.New("invalid plan")
+	ErrBadFallback  = errors.New("unknown fallback")
+	ErrNoScope      = errors.New("team_name or operation_id is required")
+	ErrNeedsOp      = errors.New("restore_reviews requires operation_id")
+
+	ErrApprovalRequired = errors.New("approved_by is required: a team lead must approve the operation")
+	ErrNotApprover      = errors.New("approved_by is not an active lead of every affected team")
+)
+
+// Fallback decides what happens to a reviewer nobody can replace.
+type Fallback string
+
+const (
+	// FallbackNone leaves the reviewer in place and reports the PR.
+	FallbackNone Fallback = ""
+	// FallbackCrossTeam picks a replacement among active users of any team.
+	FallbackCrossTeam Fallback = "cross_team"
+	// FallbackRemove drops the reviewer from the PR.
+	FallbackRemove Fallback = "remove"
+	// FallbackLead assigns the nearest lead of the author's team or its ancestors.
+	FallbackLead Fallback = "lead"
+	// FallbackDepartment picks among active users of the author team's department.
+	FallbackDepartment Fallback = "department"
+)
+
+func ParseFallback(value string) (Fallback, error) {
+	switch f := Fallback(value); f {
+	case FallbackNone, FallbackCrossTeam, FallbackRemove, FallbackLead, FallbackDepartment:
+		return f, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrBadFallback, value)
+	}
+}
+
+type Options struct {
+	Fallback Fallback
+	// AuthorTeam limits planning to PRs authored by members of that team and
+	// takes replacements from it; other PRs are reported as unresolved.
+	AuthorTeam string
+	// LeavingTeams limits the handover of a user to PRs of the listed teams.
+	LeavingTeams map[string][]string
+	// ReviewerTeam replaces each PR author's team as the source of reviewers.
+	ReviewerTeam string
+	ApprovedBy   string
+	// Progress, when set, is called after each affected PR is planned.
+	Progress func(done, total int)
+}
+
+type PullRequestsRepository interface {
+	GetOpenPRsWithInactiveReviewers(ctx context.Context, inactiveUserIDs []uuid.UUID) ([]models.PullRequest, error)
+	GetPullRequestsByIDs(ctx context.Context, ids []string) ([]models.PullRequest, error)
+}
+
+type UsersRepository interface {
+	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error)
+	GetTeamNames(ctx context.Context, id uuid.UUID) ([]string, error)
+	GetAllActive(ctx context.Context) ([]*models.User, error)
+	GetActiveInTeams(ctx context.Context, teamIDs []uuid.UUID) ([]*models.User, error)
+	DeactivateUsers(ctx context.Context, op models.BulkOperation, ids []uuid.UUID, reassignments []models.ReviewerReassignment, check func(openPRs []models.PullRequest) error) error
+	ReactivateUsers(ctx context.Context, operationID uuid.UUID, ids []uuid.UUID, restorations []models.ReviewerReassignment) error
+	EraseUser(ctx context.Context, erasure *models.UserErasure, reassignments []models.ReviewerReassignment) error
+	GetUserErasures(ctx context.Context, userID uuid.NullUUID) ([]models.UserErasure, error)
+}
+
+type TeamsRepository interface {
+	GetTeamByName(ctx context.Context, name string) (*models.Team, error)
+	GetSubtreeTeamIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+	GetEscalationLeads(ctx context.Context, id uuid.UUID) ([]*models.User, error)
+}
+
+type OperationsRepository interface {
+	GetBulkOperation(ctx context.Context, id uuid.UUID) (*models.BulkOperation, error)
+}
+
+type SettingsRepository interface {
+	GetTeamSettings(ctx context.Context, teamID uuid.UUID) (*models.TeamSettingsVersion, error)
+}
+
+type Service struct {
+	prRepo       PullRequestsRepository
+	usersRepo    UsersRepository
+	teamsRepo    TeamsRepository
+	opsRepo      OperationsRepository
+	settingsRepo SettingsRepository
+	metrics      *metrics.Registry
+	planKey      []byte
+}
+
+// New returns the bulk service. planKey signs the tokens of dry-run plans.
+func New(prRepo PullRequestsRepository, usersRepo UsersRepository, teamsRepo TeamsRepository, opsRepo OperationsRepository, settingsRepo SettingsRepository, registry *metrics.Registry, planKey []byte) *Service {
+	return &Service{
+		prRepo:       prRepo,
+		usersRepo:    usersRepo,
+		teamsRepo:    teamsRepo,
+		opsRepo:      opsRepo,
+		settingsRepo: settingsRepo,
+		metrics:      registry,
+		planKey:      planKey,
+	}
+}
+
+// DeactivateTeam deactivates every active member of the team.
+func (s *Service) DeactivateTeam(ctx context.Context, teamName string, opts Options) (*models.BulkDeactivation, error) {
+	userIDs, err := s.activeTeamMembers(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if len(userIDs) == 0 {
+		return emptyResult(), nil
+	}
+
+	return s.deactivate(ctx, userIDs, teamName, opts)
+}
+
+// PlanTeamDeactivation is the dry-run counterpart of DeactivateTeam.
+func (s *Service) PlanTeamDeactivation(ctx context.Context, teamName string, opts Options) (*models.BulkDeactivation, error) {
+	userIDs, err := s.activeTeamMembers(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if len(userIDs) == 0 {
+		return emptyResult(), nil
+	}
+
+	return s.PlanDeactivation(ctx, userIDs, opts)
+}
+
+// ApplyTeamPlan applies a plan returned by PlanTeamDeactivation.
+func (s *Service) ApplyTeamPlan(ctx context.Context, teamName, approvedBy string, plan models.BulkDeactivation) (*models.BulkDeactivation, error) {
+	userIDs, err := s.activeTeamMembers(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if len(userIDs) == 0 {
+		return nil, ErrPlanStale
+	}
+
+	return s.applyPlan(ctx, userIDs, teamName, approvedBy, plan)
+}
+
+func (s *Service) DeactivateUsers(ctx context.Context, userIDs []string, opts Options) (*models.BulkDeactivation, error) {
+	return s.deactivate(ctx, userIDs, "", opts)
+}
+
+func (s *Service) deactivate(ctx context.Context, userIDs []string, teamName string, opts Options) (*models.BulkDeactivation, error) {
+	deactivating, err := s.activeUsers(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(deactivating) == 0 {
+		return emptyResult(), nil
+	}
+
+	plan, reassignments, err := s.buildPlan(ctx, deactivating, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	op := newOperation(deactivating, teamName, opts.ApprovedBy)
+	if err := s.usersRepo.DeactivateUsers(ctx, op, deactivating, reassignments, nil); err != nil {
+		return nil, err
+	}
+	s.metrics.AddBulkDeactivation(len(deactivating))
+	s.recordHandover(reassignments, plan.UnresolvedPRs)
+
+	plan.OperationID = op.ID
+	return plan, nil
+}
+
+// PlanDeactivation is the dry-run counterpart of DeactivateUsers.
+func (s *Service) PlanDeactivation(ctx context.Context, userIDs []string, opts Options) (*models.BulkDeactivation, error) {
+	deactivating, err := s.activeUsers(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(deactivating) == 0 {
+		return emptyResult(), nil
+	}
+
+	plan, _, err := s.buildPlan(ctx, deactivating, opts)
+	return plan, err
+}
+
+// ApplyPlan applies the reassignments of a previously computed plan, or fails
+// with ErrPlanStale when anything the plan depends on changed.
+func (s *Service) ApplyPlan(ctx context.Context, userIDs []string, approvedBy string, plan models.BulkDeactivation) (*models.BulkDeactivation, error) {
+	return s.applyPlan(ctx, userIDs, "", approvedBy, plan)
+}
+
+func (s *Service) applyPlan(ctx context.Context, userIDs []string, teamName, approvedBy string, plan models.BulkDeactivation) (*models.BulkDeactivation, error) {
+	deactivating, err := s.activeUsers(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(deactivating) == 0 {
+		return nil, ErrPlanStale
+	}
+
+	reassignments, err := toReassignments(plan.ReassignedPRs)
+	if err != nil {
+		return nil, err
+	}
+
+	// The plan is verified against the reviewers locked by the transaction
+	// that applies it.
+	check := func(openPRs []models.PullRequest) error {
+		token, err := s.planToken(ctx, deactivating, openPRs, plan.ReassignedPRs, plan.UnresolvedPRs)
+		if err != nil {
+			return err
+		}
+		if !hmac.Equal([]byte(token), []byte(plan.PlanToken)) {
+			return ErrPlanStale
+		}
+		return s.checkPlan(ctx, deactivating, openPRs, plan.ReassignedPRs)
+	}
+
+	op := newOperation(deactivating, teamName, approvedBy)
+	if err := s.usersRepo.DeactivateUsers(ctx, op, deactivating, reassignments, check); err != nil {
+		return nil, err
+	}
+	s.metrics.AddBulkDeactivation(len(deactivating))
+	s.recordHandover(reassignments, plan.UnresolvedPRs)
+
+	return &models.BulkDeactivation{
+		OperationID:      op.ID,
+		DeactivatedUsers: uuidStrings(deactivating),
+		ReassignedPRs:    plan.ReassignedPRs,
+		UnresolvedPRs:    plan.UnresolvedPRs,
+		PlanToken:        plan.PlanToken,
+	}, nil
+}
+
+// CheckTeamApproval enforces the team's approval policy on approvedBy.
+func (s *Service) CheckTeamApproval(ctx context.Context, teamName, approvedBy string) error {
+	team, err := s.teamsRepo.GetTeamByName(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	return s.checkApproval(ctx, []*models.Team{team}, approvedBy)
+}
+
+// CheckUsersApproval enforces the approval policy of every team the users
+// belong to.
+func (s *Service) CheckUsersApproval(ctx context.Context, userIDs []string, approvedBy string) error {
+	seen := map[string]bool{}
+	var teams []*models.Team
+	for _, id := range userIDs {
+		uid, err := uuid.Parse(id)
+		if err != nil {
+			return fmt.Errorf("invalid user_id %s: %w", id, err)
+		}
+
+		names, err := s.usersRepo.GetTeamNames(ctx, uid)
+		if err != nil {
+			return fmt.Errorf("get teams: %w", err)
+		}
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			team, err := s.teamsRepo.GetTeamByName(ctx, name)
+			if err != nil {
+				return err
+			}
+			teams = append(teams, team)
+		}
+	}
+	return s.checkApproval(ctx, teams, approvedBy)
+}
+
+func (s *Service) reviewerCount(ctx context.Context, team *models.Team) (int, error) {
+	count := models.DefaultTeamSettings().ReviewerCount
+	if team != nil {
+		v, err := s.settingsRepo.GetTeamSettings(ctx, uuid.MustParse(team.ID))
+		if err != nil {
+			return 0, err
+		}
+		if v != nil {
+			count = v.Settings.ReviewerCount
+		}
+	}
+	return min(count, models.MaxReviewers), nil
+}
+
+func (s *Service) checkApproval(ctx context.Context, teams []*models.Team, approvedBy string) error {
+	for _, team := range teams {
+		policy := models.DefaultTeamSettings().ApprovalPolicy
+		v, err := s.settingsRepo.GetTeamSettings(ctx, uuid.MustParse(team.ID))
+		if err != nil {
+			return err
+		}
+		if v != nil {
+			policy = v.Settings.ApprovalPolicy
+		}
+		if policy == models.ApprovalNone {
+			continue
+		}
+
+		var leads []string
+		for _, m := range team.Members {
+			if m.Role == models.TeamRoleLead && m.IsActive {
+				leads = append(leads, m.ID)
+			}
+		}
+		if len(leads) == 0 && policy == models.ApprovalLeadIfPresent {
+			continue
+		}
+		if approvedBy == "" {
+			return ErrApprovalRequired
+		}
+		approved := false
+		for _, id := range leads {
+			if id == approvedBy {
+				approved = true
+				break
+			}
+		}
+		if !approved {
+			return fmt.Errorf("%w: %s", ErrNotApprover, team.Name)
+		}
+	}
+	return nil
+}
+
+// PlanHandover plans moving the users' open reviews without deactivating them.
+func (s *Service) PlanHandover(ctx context.Context, userIDs []uuid.UUID, opts Options) (*models.BulkDeactivation, []models.ReviewerReassignment, error) {
+	return s.buildPlan(ctx, userIDs, opts)
+}
+
+// ReactivateRequest selects users to bring back: the inactive members of
+// TeamName, the users of OperationID, or both intersected.
+type ReactivateRequest struct {
+	TeamName       string
+	OperationID    string
+	RestoreReviews bool
+}
+
+// Reactivate is the inverse of a bulk deactivation.
+func (s *Service) Reactivate(ctx context.Context, req ReactivateRequest) (*models.BulkReactivation, error) {
+	if req.TeamName == "" && req.OperationID == "" {
+		return nil, ErrNoScope
+	}
+	if req.RestoreReviews && req.OperationID == "" {
+		return nil, ErrNeedsOp
+	}
+
+	var op *models.BulkOperation
+	if req.OperationID != "" {
+		opID, err := uuid.Parse(req.OperationID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid operation_id %s: %w", req.OperationID, err)
+		}
+		op, err = s.opsRepo.GetBulkOperation(ctx, opID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	scope := map[string]bool{}
+	if op != nil {
+		for _, id := range op.UserIDs {
+			scope[id] = true
+		}
+	}
+
+	var candidates []uuid.UUID
+	if req.TeamName != "" {
+		team, err := s.teamsRepo.GetTeamByName(ctx, req.TeamName)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range team.Members {
+			if op != nil && !scope[member.ID] {
+				continue
+			}
+			candidates = append(candidates, uuid.MustParse(member.ID))
+		}
+	} else {
+		for _, id := range op.UserIDs {
+			candidates = append(candidates, uuid.MustParse(id))
+		}
+	}
+
+	result := &models.BulkReactivation{
+		ReactivatedUsers: []string{},
+		RestoredPRs:      []models.PRReassignment{},
+	}
+	if op != nil {
+		result.OperationID = op.ID
+	}
+
+	var reactivating []uuid.UUID
+	inScope := map[string]bool{}
+	if len(candidates) > 0 {
+		users, err := s.usersRepo.GetByIDs(ctx, candidates)
+		if err != nil {
+			return nil, fmt.Errorf("get users: %w", err)
+		}
+		for _, u := range users {
+			inScope[u.ID] = true
+			if !u.IsActive {
+				reactivating = append(reactivating, uuid.MustParse(u.ID))
+				result.ReactivatedUsers = append(result.ReactivatedUsers, u.ID)
+			}
+		}
+	}
+
+	var restorations []models.ReviewerReassignment
+	if req.RestoreReviews {
+		var err error
+		restorations, result.RestoredPRs, err = s.planRestorations(ctx, op, inScope)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var opID uuid.UUID
+	if op != nil {
+		opID = uuid.MustParse(op.ID)
+	}
+	if len(reactivating) == 0 && len(restorations) == 0 {
+		return result, nil
+	}
+	if err := s.usersRepo.ReactivateUsers(ctx, opID, reactivating, restorations); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Service) planRestorations(ctx context.Context, op *models.BulkOperation, scope map[string]bool) ([]models.ReviewerReassignment, []models.PRReassignment, error) {
+	var prIDs []string
+	seen := map[string]bool{}
+	for _, item := range op.Reassignments {
+		if !seen[item.PRID] {
+			seen[item.PRID] = true
+			prIDs = append(prIDs, item.PRID)
+		}
+	}
+	if len(prIDs) == 0 {
+		return nil, []models.PRReassignment{}, nil
+	}
+
+	prs, err := s.prRepo.GetPullRequestsByIDs(ctx, prIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	teams, err := s.authorTeams(ctx, prs)
+	if err != nil {
+		return nil, nil, err
+	}
+	reviewers := map[string]map[string]bool{}
+	slots := map[string]int{}
+	counts := map[*models.Team]int{}
+	for _, pr := range prs {
+		if pr.Status != models.PullRequestStatusOpen {
+			continue
+		}
+		current := map[string]bool{}
+		for _, id := range pr.Reviewers {
+			current[id] = true
+		}
+		reviewers[pr.ID] = current
+
+		team := teams[pr.AuthorID]
+		count, ok := counts[team]
+		if !ok {
+			if count, err = s.reviewerCount(ctx, team); err != nil {
+				return nil, nil, err
+			}
+			counts[team] = count
+		}
+		slots[pr.ID] = count
+	}
+
+	var restorations []models.ReviewerReassignment
+	restored := map[string]*models.PRReassignment{}
+	var order []string
+
+	for _, item := range op.Reassignments {
+		current, open := reviewers[item.PRID]
+		original := item.OldReviewerID.String()
+		if !open || !scope[original] || current[original] {
+			continue
+		}
+
+		restoration := models.ReviewerReassignment{
+			PRID:          item.PRID,
+			OldReviewerID: item.NewReviewerID,
+			NewReviewerID: item.OldReviewerID,
+		}
+		if item.NewReviewerID == uuid.Nil {
+			if len(current) >= slots[item.PRID] {
+				continue
+			}
+		} else if !current[item.NewReviewerID.String()] {
+			continue
+		}
+
+		restorations = append(restorations, restoration)
+		current[original] = true
+		if item.NewReviewerID != uuid.Nil {
+			delete(current, item.NewReviewerID.String())
+		}
+
+		info, ok := restored[item.PRID]
+		if !ok {
+			info = &models.PRReassignment{PRID: item.PRID}
+			restored[item.PRID] = info
+			order = append(order, item.PRID)
+		}
+		if item.NewReviewerID != uuid.Nil {
+			info.Replaced = append(info.Replaced, item.NewReviewerID.String())
+		}
+		info.NewReviewers = append(info.NewReviewers, original)
+	}
+
+	restoredPRs := make([]models.PRReassignment, 0, len(order))
+	for _, prID := range order {
+		restoredPRs = append(restoredPRs, *restored[prID])
+	}
+	return restorations, restoredPRs, nil
+}
+
+func (s *Service) activeTeamMembers(ctx context.Context, teamName string) ([]string, error) {
+	team, err := s.teamsRepo.GetTeamByName(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	var activeUserIDs []string
+	for _, user := range team.Members {
+		if user.IsActive {
+			activeUserIDs = append(activeUserIDs, user.ID)
+		}
+	}
+	return activeUserIDs, nil
+}
+
+func (s *Service) activeUsers(ctx context.Context, userIDs []string) ([]uuid.UUID, error) {
+	if len(userIDs) == 0 {
+		return nil, ErrNoUsers
+	}
+
+	uuids := make([]uuid.UUID, 0, len(userIDs))
+	for _, id := range userIDs {
+		uid, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user_id %s: %w", id, err)
+		}
+		uuids = append(uuids, uid)
+	}
+
+	users, err := s.usersRepo.GetByIDs(ctx, uuids)
+	if err != nil {
+		return nil, fmt.Errorf("get users: %w", err)
+	}
+
+	found := make(map[string]bool, len(users))
+	active := []uuid.UUID{}
+	for _, u := range users {
+		found[u.ID] = true
+		if u.IsActive {
+			active = append(active, uuid.MustParse(u.ID))
+		}
+	}
+	for _, uid := range uuids {
+		if !found[uid.String()] {
+			return nil, fmt.Errorf("%w: %s", ErrUserNotFound, uid)
+		}
+	}
+
+	return active, nil
+}
+
+func (s *Service) buildPlan(ctx context.Context, deactivating []uuid.UUID, opts Options) (*models.BulkDeactivation, []models.ReviewerReassignment, error) {
+	openPRs, err := s.prRepo.GetOpenPRsWithInactiveReviewers(ctx, deactivating)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deactivatingIDs := uuidSet(deactivating)
+
+	authorTeams, err := s.authorTeams(ctx, openPRs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sourceTeam *models.Team
+	sourceMembers := map[string]bool{}
+	if opts.AuthorTeam != "" {
+		sourceTeam, err = s.teamsRepo.GetTeamByName(ctx, opts.AuthorTeam)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, m := range sourceTeam.Members {
+			sourceMembers[m.ID] = true
+		}
+	}
+
+	var reviewerTeam *models.Team
+	if opts.ReviewerTeam != "" {
+		reviewerTeam, err = s.teamsRepo.GetTeamByName(ctx, opts.ReviewerTeam)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	reassignedPRs := []models.PRReassignment{}
+	unresolved := []models.UnresolvedPR{}
+
+	var crossTeamPool []*models.User
+	departmentPools := map[string][]*models.User{}
+	escalationLeads := map[string][]*models.User{}
+
+	for i, pr := range openPRs {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		if opts.Progress != nil {
+			opts.Progress(i, len(openPRs))
+		}
+
+		team := authorTeams[pr.AuthorID]
+
+		var inactiveReviewers []string
+		for _, reviewerID := range pr.Reviewers {
+			if deactivatingIDs[reviewerID] && handsOver(opts, reviewerID, team) {
+				inactiveReviewers = append(inactiveReviewers, reviewerID)
+			}
+		}
+
+		if len(inactiveReviewers) == 0 {
+			continue
+		}
+
+		if sourceTeam != nil {
+			if !sourceMembers[pr.AuthorID] {
+				unresolved = append(unresolved, models.UnresolvedPR{
+					PRID:      pr.ID,
+					Reviewers: inactiveReviewers,
+					Reason:    models.UnresolvedReasonOutsideTeam,
+				})
+				continue
+			}
+			team = sourceTeam
+		}
+		if reviewerTeam != nil {
+			team = reviewerTeam
+		}
+		if team == nil {
+			unresolved = append(unresolved, models.UnresolvedPR{
+				PRID:      pr.ID,
+				Reviewers: inactiveReviewers,
+				Reason:    models.UnresolvedReasonAuthorNotFound,
+			})
+			continue
+		}
+
+		excludeIDs := map[string]bool{
+			pr.AuthorID: true,
+		}
+		for _, reviewerID := range pr.Reviewers {
+			excludeIDs[reviewerID] = true
+		}
+
+		available := func(id string, isActive bool) bool {
+			return isActive && !excludeIDs[id] && !deactivatingIDs[id]
+		}
+
+		var candidates []string
+		for _, member := range team.Reviewers() {
+			if available(member.ID, member.IsActive) {
+				candidates = append(candidates, member.ID)
+			}
+		}
+
+		rand.Shuffle(len(candidates), func(i, j int) {
+			candidates[i], candidates[j] = candidates[j], candidates[i]
+		})
+
+		item := models.PRReassignment{PRID: pr.ID}
+		left := inactiveReviewers
+		for len(left) > 0 && len(candidates) > 0 {
+			item.Replaced = append(item.Replaced, left[0])
+			item.NewReviewers = append(item.NewReviewers, candidates[0])
+			excludeIDs[candidates[0]] = true
+			left, candidates = left[1:], candidates[1:]
+		}
+
+		reason := models.UnresolvedReasonNoCandidate
+		if len(left) > 0 {
+			switch opts.Fallback {
+			case FallbackCrossTeam, FallbackDepartment:
+				var users []*models.User
+				if opts.Fallback == FallbackDepartment {
+					users, err = s.departmentPool(ctx, team, departmentPools)
+					if err != nil {
+						return nil, nil, err
+					}
+				} else {
+					if crossTeamPool == nil {
+						crossTeamPool, err = s.usersRepo.GetAllActive(ctx)
+						if err != nil {
+							return nil, nil, fmt.Errorf("get active users: %w", err)
+						}
+					}
+					users = crossTeamPool
+				}
+
+				var pool []string
+				for _, u := range users {
+					if available(u.ID, u.IsActive) {
+						pool = append(pool, u.ID)
+					}
+				}
+				rand.Shuffle(len(pool), func(i, j int) {
+					pool[i], pool[j] = pool[j], pool[i]
+				})
+
+				for len(left) > 0 && len(pool) > 0 {
+					item.Replaced = append(item.Replaced, left[0])
+					item.NewReviewers = append(item.NewReviewers, pool[0])
+					excludeIDs[pool[0]] = true
+					left, pool = left[1:], pool[1:]
+				}
+				item.Fallback = string(opts.Fallback)
+
+			case FallbackRemove:
+				item.Removed = left
+				left = nil
+				item.Fallback = string(FallbackRemove)
+
+			case FallbackLead:
+				leads, ok := escalationLeads[team.ID]
+				if !ok {
+					leads, err = s.teamsRepo.GetEscalationLeads(ctx, uuid.MustParse(team.ID))
+					if err != nil {
+						return nil, nil, fmt.Errorf("get leads: %w", err)
+					}
+					escalationLeads[team.ID] = leads
+				}
+				for _, lead := range leads {
+					if len(left) == 0 {
+						break
+					}
+					if available(lead.ID, lead.IsActive) {
+						item.Replaced = append(item.Replaced, left[0])
+						item.NewReviewers = append(item.NewReviewers, lead.ID)
+						excludeIDs[lead.ID] = true
+						left = left[1:]
+						item.Fallback = string(FallbackLead)
+					}
+				}
+				if len(left) > 0 {
+					reason = models.UnresolvedReasonNoLead
+				}
+			}
+		}
+
+		if len(item.Replaced) > 0 || len(item.Removed) > 0 {
+			reassignedPRs = append(reassignedPRs, item)
+		}
+		if len(left) > 0 {
+			unresolved = append(unresolved, models.UnresolvedPR{
+				PRID:      pr.ID,
+				Reviewers: left,
+				Reason:    reason,
+			})
+		}
+	}
+
+	if opts.Progress != nil {
+		opts.Progress(len(openPRs), len(openPRs))
+	}
+
+	token, err := s.planToken(ctx, deactivating, openPRs, reassignedPRs, unresolved)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reassignments, err := toReassignments(reassignedPRs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &models.BulkDeactivation{
+		DeactivatedUsers: uuidStrings(deactivating),
+		ReassignedPRs:    reassignedPRs,
+		UnresolvedPRs:    unresolved,
+		PlanToken:        token,
+	}, reassignments, nil
+}
+
+func (s *Service) departmentPool(ctx context.Context, team *models.Team, cache map[string][]*models.User) ([]*models.User, error) {
+	root := team.ParentID
+	if root == "" {
+		root = team.ID
+	}
+	if users, ok := cache[root]; ok {
+		return users, nil
+	}
+
+	rootID, err := uuid.Parse(root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid team_id %s: %w", root, err)
+	}
+	teamIDs, err := s.teamsRepo.GetSubtreeTeamIDs(ctx, rootID)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.usersRepo.GetActiveInTeams(ctx, teamIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get department users: %w", err)
+	}
+
+	cache[root] = users
+	return users, nil
+}
+
+// authorTeams leaves out authors that cannot be resolved.
+func (s *Service) authorTeams(ctx context.Context, prs []models.PullRequest) (map[string]*models.Team, error) {
+	seen := map[string]bool{}
+	var authorIDs []uuid.UUID
+	for _, pr := range prs {
+		if seen[pr.AuthorID] {
+			continue
+		}
+		seen[pr.AuthorID] = true
+		if id, err := uuid.Parse(pr.AuthorID); err == nil {
+			authorIDs = append(authorIDs, id)
+		}
+	}
+
+	result := map[string]*models.Team{}
+	if len(authorIDs) == 0 {
+		return result, nil
+	}
+
+	authors, err := s.usersRepo.GetByIDs(ctx, authorIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get authors: %w", err)
+	}
+
+	teams := map[string]*models.Team{}
+	for _, author := range authors {
+		team, ok := teams[author.TeamName]
+		if !ok {
+			team, err = s.teamsRepo.GetTeamByName(ctx, author.TeamName)
+			if err != nil {
+				team = nil
+			}
+			teams[author.TeamName] = team
+		}
+		if team != nil {
+			result[author.ID] = team
+		}
+	}
+
+	return result, nil
+}
+
+// planToken changes whenever the users, their open PRs or the plan change.
+func (s *Service) planToken(ctx context.Context, deactivating []uuid.UUID, openPRs []models.PullRequest, reassigned []models.PRReassignment, unresolved []models.UnresolvedPR) (string, error) {
+	var b strings.Builder
+
+	userIDs := uuidStrings(deactivating)
+	sort.Strings(userIDs)
+	b.WriteString("users:" + strings.Join(userIDs, ",") + "\n")
+
+	prs := append([]models.PullRequest(nil), openPRs...)
+	sort.Slice(prs, func(i, j int) bool { return prs[i].ID < prs[j].ID })
+	for _, pr := range prs {
+		b.WriteString("pr:" + pr.ID + "|" + string(pr.Status) + "|" + strings.Join(pr.Reviewers, ",") + "\n")
+	}
+
+	items := append([]models.PRReassignment(nil), reassigned...)
+	sort.Slice(items, func(i, j int) bool { return items[i].PRID < items[j].PRID })
+
+	var newIDs []uuid.UUID
+	for _, item := range items {
+		b.WriteString("reassign:" + item.PRID + "|" + strings.Join(item.Replaced, ",") + "|" + strings.Join(item.NewReviewers, ",") +
+			"|" + strings.Join(item.Removed, ",") + "|" + item.Fallback + "\n")
+		for _, id := range item.NewReviewers {
+			uid, err := uuid.Parse(id)
+			if err != nil {
+				return "", fmt.Errorf("%w: invalid reviewer %s", ErrInvalidPlan, id)
+			}
+			newIDs = append(newIDs, uid)
+		}
+	}
+
+	left := append([]models.UnresolvedPR(nil), unresolved...)
+	sort.Slice(left, func(i, j int) bool { return left[i].PRID < left[j].PRID })
+	for _, item := range left {
+		b.WriteString("unresolved:" + item.PRID + "|" + strings.Join(item.Reviewers, ",") + "|" + item.Reason + "\n")
+	}
+
+	if len(newIDs) > 0 {
+		users, err := s.usersRepo.GetByIDs(ctx, newIDs)
+		if err != nil {
+			return "", fmt.Errorf("get reviewers: %w", err)
+		}
+		sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+		for _, u := range users {
+			b.WriteString("reviewer:" + u.ID + "|" + u.TeamName + "|" + strconv.FormatBool(u.IsActive) + "\n")
+		}
+	}
+
+	mac := hmac.New(sha256.New, s.planKey)
+	mac.Write([]byte(b.String()))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+func (s *Service) checkPlan(ctx context.Context, deactivating []uuid.UUID, openPRs []models.PullRequest, reassigned []models.PRReassignment) error {
+	deactivatingIDs := uuidSet(deactivating)
+	prs := map[string]models.PullRequest{}
+	for _, pr := range openPRs {
+		prs[pr.ID] = pr
+	}
+
+	var newIDs []uuid.UUID
+	for _, item := range reassigned {
+		pr, ok := prs[item.PRID]
+		if !ok {
+			return fmt.Errorf("%w: pr %s", ErrInvalidPlan, item.PRID)
+		}
+		reviewers := map[string]bool{}
+		for _, id := range pr.Reviewers {
+			reviewers[id] = true
+		}
+		for _, id := range append(append([]string(nil), item.Replaced...), item.Removed...) {
+			if !reviewers[id] || !deactivatingIDs[id] {
+				return fmt.Errorf("%w: %s is not a deactivating reviewer of pr %s", ErrInvalidPlan, id, pr.ID)
+			}
+		}
+
+		taken := map[string]bool{pr.AuthorID: true}
+		for id := range reviewers {
+			taken[id] = true
+		}
+		for _, id := range item.NewReviewers {
+			uid, err := uuid.Parse(id)
+			if err != nil || taken[id] || deactivatingIDs[id] {
+				return fmt.Errorf("%w: %s cannot review pr %s", ErrInvalidPlan, id, pr.ID)
+			}
+			taken[id] = true
+			newIDs = append(newIDs, uid)
+		}
+	}
+	if len(newIDs) == 0 {
+		return nil
+	}
+
+	users, err := s.usersRepo.GetByIDs(ctx, newIDs)
+	if err != nil {
+		return fmt.Errorf("get reviewers: %w", err)
+	}
+	active := map[string]bool{}
+	for _, u := range users {
+		active[u.ID] = u.IsActive
+	}
+	for _, id := range newIDs {
+		if !active[id.String()] {
+			return fmt.Errorf("%w: reviewer %s is not active", ErrInvalidPlan, id)
+		}
+	}
+	return nil
+}
+
+func handsOver(opts Options, reviewerID string, team *models.Team) bool {
+	teams, ok := opts.LeavingTeams[reviewerID]
+	if !ok {
+		return true
+	}
+	if team == nil {
+		return false
+	}
+	for _, name := range teams {
+		if name == team.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func toReassignments(items []models.PRReassignment) ([]models.ReviewerReassignment, error) {
+	var reassignments []models.ReviewerReassignment
+	for _, item := range items {
+		if len(item.Replaced) != len(item.NewReviewers) {
+			return nil, fmt.Errorf("%w: pr %s", ErrInvalidPlan, item.PRID)
+		}
+		for i := range item.Replaced {
+			oldID, err := uuid.Parse(item.Replaced[i])
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid reviewer %s", ErrInvalidPlan, item.Replaced[i])
+			}
+			newID, err := uuid.Parse(item.NewReviewers[i])
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid reviewer %s", ErrInvalidPlan, item.NewReviewers[i])
+			}
+			reassignments = append(reassignments, models.ReviewerReassignment{
+				PRID:          item.PRID,
+				OldReviewerID: oldID,
+				NewReviewerID: newID,
+			})
+		}
+		for _, id := range item.Removed {
+			oldID, err := uuid.Parse(id)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid reviewer %s", ErrInvalidPlan, id)
+			}
+			reassignments = append(reassignments, models.ReviewerReassignment{
+				PRID:          item.PRID,
+				OldReviewerID: oldID,
+			})
+		}
+	}
+	return reassignments, nil
+}
+
+func newOperation(userIDs []uuid.UUID, teamName, approvedBy string) models.BulkOperation {
+	kind := models.BulkOperationDeactivateUsers
+	if teamName != "" {
+		kind = models.BulkOperationDeactivateTeam
+	}
+	return models.BulkOperation{
+		ID:         uuid.New().String(),
+		Kind:       kind,
+		TeamName:   teamName,
+		ApprovedBy: approvedBy,
+		UserIDs:    uuidStrings(userIDs),
+	}
+}
+
+func (s *Service) recordHandover(reassignments []models.ReviewerReassignment, unresolved []models.UnresolvedPR) {
+	s.metrics.AddReassignments(len(reassignments))
+	noCandidates := 0
+	for _, pr := range unresolved {
+		if pr.Reason == models.UnresolvedReasonNoCandidate {
+			noCandidates++
+		}
+	}
+	s.metrics.AddNoCandidates(noCandidates)
+}
+
+func emptyResult() *models.BulkDeactivation {
+	return &models.BulkDeactivation{
+		DeactivatedUsers: []string{},
+		ReassignedPRs:    []models.PRReassignment{},
+		UnresolvedPRs:    []models.UnresolvedPR{},
+	}
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	result := make([]string, len(ids))
+	for i, id := range ids {
+		result[i] = id.String()
+	}
+	return result
+}
+
+func uuidSet(ids []uuid.UUID) map[string]bool {
+	result := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		result[id.String()] = true
+	}
+	return result
+}
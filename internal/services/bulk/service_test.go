@@ -0,0 +1,475 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reviewer-service/internal/models"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore keeps users, teams and PRs in memory for the repositories
+// buildPlan reads from.
+type fakeStore struct {
+	users   map[string]*models.User
+	teams   map[string]*models.Team
+	subtree map[string][]uuid.UUID
+	leads   map[string][]*models.User
+	prs     []models.PullRequest
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		users:   map[string]*models.User{},
+		teams:   map[string]*models.Team{},
+		subtree: map[string][]uuid.UUID{},
+		leads:   map[string][]*models.User{},
+	}
+}
+
+// testID returns a stable UUID for n.
+func testID(n int) string {
+	return fmt.Sprintf("00000000-0000-0000-0000-%012d", n)
+}
+
+func (f *fakeStore) addTeam(name string, id int, parentID string) *models.Team {
+	team := &models.Team{ID: testID(id), Name: name, ParentID: parentID}
+	f.teams[name] = team
+	return team
+}
+
+func (f *fakeStore) addUser(team *models.Team, id int, role models.TeamRole, isActive bool) string {
+	user := models.User{ID: testID(id), Username: fmt.Sprintf("user%d", id), TeamName: team.Name, IsActive: isActive, Role: role}
+	f.users[user.ID] = &user
+	team.Members = append(team.Members, user)
+	return user.ID
+}
+
+func (f *fakeStore) addPR(id, authorID string, reviewers ...string) {
+	f.prs = append(f.prs, models.PullRequest{
+		ID:        id,
+		AuthorID:  authorID,
+		Status:    models.PullRequestStatusOpen,
+		Reviewers: reviewers,
+	})
+}
+
+func (f *fakeStore) GetOpenPRsWithInactiveReviewers(ctx context.Context, inactiveUserIDs []uuid.UUID) ([]models.PullRequest, error) {
+	inactive := uuidSet(inactiveUserIDs)
+	var result []models.PullRequest
+	for _, pr := range f.prs {
+		for _, id := range pr.Reviewers {
+			if pr.Status == models.PullRequestStatusOpen && inactive[id] {
+				result = append(result, pr)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeStore) GetPullRequestsByIDs(ctx context.Context, ids []string) ([]models.PullRequest, error) {
+	var result []models.PullRequest
+	for _, pr := range f.prs {
+		for _, id := range ids {
+			if pr.ID == id {
+				result = append(result, pr)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeStore) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error) {
+	var result []*models.User
+	for _, id := range ids {
+		if u, ok := f.users[id.String()]; ok {
+			result = append(result, u)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeStore) GetTeamNames(ctx context.Context, id uuid.UUID) ([]string, error) {
+	var names []string
+	for name, team := range f.teams {
+		for _, m := range team.Members {
+			if m.ID == id.String() {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (f *fakeStore) GetAllActive(ctx context.Context) ([]*models.User, error) {
+	var result []*models.User
+	for _, u := range f.users {
+		if u.IsActive {
+			result = append(result, u)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeStore) GetActiveInTeams(ctx context.Context, teamIDs []uuid.UUID) ([]*models.User, error) {
+	ids := uuidSet(teamIDs)
+	seen := map[string]bool{}
+	var result []*models.User
+	for _, team := range f.teams {
+		if !ids[team.ID] {
+			continue
+		}
+		for _, m := range team.Members {
+			if u := f.users[m.ID]; u.IsActive && !seen[u.ID] {
+				seen[u.ID] = true
+				result = append(result, u)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeStore) DeactivateUsers(ctx context.Context, op models.BulkOperation, ids []uuid.UUID, reassignments []models.ReviewerReassignment, check func(openPRs []models.PullRequest) error) error {
+	return errors.New("not implemented")
+}
+
+func (f *fakeStore) ReactivateUsers(ctx context.Context, operationID uuid.UUID, ids []uuid.UUID, restorations []models.ReviewerReassignment) error {
+	return errors.New("not implemented")
+}
+
+func (f *fakeStore) EraseUser(ctx context.Context, erasure *models.UserErasure, reassignments []models.ReviewerReassignment) error {
+	return errors.New("not implemented")
+}
+
+func (f *fakeStore) GetUserErasures(ctx context.Context, userID uuid.NullUUID) ([]models.UserErasure, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeStore) GetTeamByName(ctx context.Context, name string) (*models.Team, error) {
+	team, ok := f.teams[name]
+	if !ok {
+		return nil, models.ErrTeamNotFound
+	}
+	return team, nil
+}
+
+func (f *fakeStore) GetSubtreeTeamIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	return f.subtree[id.String()], nil
+}
+
+func (f *fakeStore) GetEscalationLeads(ctx context.Context, id uuid.UUID) ([]*models.User, error) {
+	return f.leads[id.String()], nil
+}
+
+func newTestService(store *fakeStore) *Service {
+	return New(store, store, store, nil, nil, nil, []byte("test-plan-key"))
+}
+
+func uuids(ids ...string) []uuid.UUID {
+	result := make([]uuid.UUID, len(ids))
+	for i, id := range ids {
+		result[i] = uuid.MustParse(id)
+	}
+	return result
+}
+
+// handoverFixture has a backend team whose two reviewers leave and nobody in
+// the team can replace them. Backend sits under platform next to frontend;
+// mobile is a separate root team.
+type handoverFixture struct {
+	store                      *fakeStore
+	backend                    *models.Team
+	author, leaving1, leaving2 string
+	frontendUser, mobileUser   string
+}
+
+func newHandoverFixture() *handoverFixture {
+	store := newFakeStore()
+	platform := store.addTeam("platform", 100, "")
+	backend := store.addTeam("backend", 101, platform.ID)
+	frontend := store.addTeam("frontend", 102, platform.ID)
+	mobile := store.addTeam("mobile", 103, "")
+	store.subtree[platform.ID] = uuids(platform.ID, backend.ID, frontend.ID)
+
+	f := &handoverFixture{store: store, backend: backend}
+	f.author = store.addUser(backend, 1, models.TeamRoleMember, true)
+	f.leaving1 = store.addUser(backend, 2, models.TeamRoleMember, true)
+	f.leaving2 = store.addUser(backend, 3, models.TeamRoleMember, true)
+	f.frontendUser = store.addUser(frontend, 4, models.TeamRoleMember, true)
+	f.mobileUser = store.addUser(mobile, 5, models.TeamRoleMember, true)
+	store.addPR("pr-1", f.author, f.leaving1, f.leaving2)
+	return f
+}
+
+func (f *handoverFixture) plan(t *testing.T, opts Options) *models.BulkDeactivation {
+	t.Helper()
+	plan, _, err := newTestService(f.store).buildPlan(context.Background(), uuids(f.leaving1, f.leaving2), opts)
+	require.NoError(t, err)
+	return plan
+}
+
+func TestBuildPlanReplacesFromAuthorTeam(t *testing.T) {
+	f := newHandoverFixture()
+	spare := f.store.addUser(f.backend, 6, models.TeamRoleMember, true)
+	f.store.addUser(f.backend, 7, models.TeamRoleObserver, true)
+
+	plan, reassignments, err := newTestService(f.store).buildPlan(context.Background(), uuids(f.leaving1), Options{})
+	require.NoError(t, err)
+
+	require.Len(t, plan.ReassignedPRs, 1)
+	assert.Equal(t, []string{f.leaving1}, plan.ReassignedPRs[0].Replaced)
+	assert.Equal(t, []string{spare}, plan.ReassignedPRs[0].NewReviewers)
+	assert.Empty(t, plan.ReassignedPRs[0].Fallback)
+	assert.Empty(t, plan.UnresolvedPRs)
+	assert.Equal(t, []models.ReviewerReassignment{{
+		PRID:          "pr-1",
+		OldReviewerID: uuid.MustParse(f.leaving1),
+		NewReviewerID: uuid.MustParse(spare),
+	}}, reassignments)
+}
+
+func TestBuildPlanWithoutFallbackReportsNoCandidate(t *testing.T) {
+	f := newHandoverFixture()
+	f.store.addUser(f.backend, 7, models.TeamRoleObserver, true)
+
+	plan := f.plan(t, Options{})
+
+	assert.Empty(t, plan.ReassignedPRs)
+	assert.Equal(t, []models.UnresolvedPR{{
+		PRID:      "pr-1",
+		Reviewers: []string{f.leaving1, f.leaving2},
+		Reason:    models.UnresolvedReasonNoCandidate,
+	}}, plan.UnresolvedPRs)
+}
+
+func TestBuildPlanFallbackRemove(t *testing.T) {
+	f := newHandoverFixture()
+
+	plan := f.plan(t, Options{Fallback: FallbackRemove})
+
+	require.Len(t, plan.ReassignedPRs, 1)
+	assert.Empty(t, plan.ReassignedPRs[0].Replaced)
+	assert.Equal(t, []string{f.leaving1, f.leaving2}, plan.ReassignedPRs[0].Removed)
+	assert.Equal(t, string(FallbackRemove), plan.ReassignedPRs[0].Fallback)
+	assert.Empty(t, plan.UnresolvedPRs)
+}
+
+func TestBuildPlanFallbackCrossTeam(t *testing.T) {
+	f := newHandoverFixture()
+	f.store.users[f.mobileUser].IsActive = false
+
+	plan := f.plan(t, Options{Fallback: FallbackCrossTeam})
+
+	require.Len(t, plan.ReassignedPRs, 1)
+	assert.Equal(t, []string{f.leaving1}, plan.ReassignedPRs[0].Replaced)
+	assert.Equal(t, []string{f.frontendUser}, plan.ReassignedPRs[0].NewReviewers)
+	assert.Equal(t, string(FallbackCrossTeam), plan.ReassignedPRs[0].Fallback)
+	assert.Equal(t, []models.UnresolvedPR{{
+		PRID:      "pr-1",
+		Reviewers: []string{f.leaving2},
+		Reason:    models.UnresolvedReasonNoCandidate,
+	}}, plan.UnresolvedPRs)
+}
+
+func TestBuildPlanFallbackDepartmentStaysInSubtree(t *testing.T) {
+	f := newHandoverFixture()
+
+	plan := f.plan(t, Options{Fallback: FallbackDepartment})
+
+	require.Len(t, plan.ReassignedPRs, 1)
+	assert.Equal(t, []string{f.frontendUser}, plan.ReassignedPRs[0].NewReviewers)
+	assert.NotContains(t, plan.ReassignedPRs[0].NewReviewers, f.mobileUser)
+	assert.Equal(t, string(FallbackDepartment), plan.ReassignedPRs[0].Fallback)
+	require.Len(t, plan.UnresolvedPRs, 1)
+	assert.Equal(t, []string{f.leaving2}, plan.UnresolvedPRs[0].Reviewers)
+}
+
+func TestBuildPlanFallbackLeadCoversEveryReviewer(t *testing.T) {
+	f := newHandoverFixture()
+	lead1 := &models.User{ID: testID(10), IsActive: true}
+	lead2 := &models.User{ID: testID(11), IsActive: true}
+	f.store.leads[f.backend.ID] = []*models.User{lead1, lead2}
+
+	plan := f.plan(t, Options{Fallback: FallbackLead})
+
+	require.Len(t, plan.ReassignedPRs, 1)
+	assert.Equal(t, []string{f.leaving1, f.leaving2}, plan.ReassignedPRs[0].Replaced)
+	assert.Equal(t, []string{lead1.ID, lead2.ID}, plan.ReassignedPRs[0].NewReviewers)
+	assert.Equal(t, string(FallbackLead), plan.ReassignedPRs[0].Fallback)
+	assert.Empty(t, plan.UnresolvedPRs)
+}
+
+func TestBuildPlanFallbackLeadReportsNoLead(t *testing.T) {
+	f := newHandoverFixture()
+	lead := &models.User{ID: testID(10), IsActive: true}
+	f.store.leads[f.backend.ID] = []*models.User{
+		{ID: testID(11), IsActive: false},
+		lead,
+		{ID: f.author, IsActive: true},
+	}
+
+	plan := f.plan(t, Options{Fallback: FallbackLead})
+
+	require.Len(t, plan.ReassignedPRs, 1)
+	assert.Equal(t, []string{lead.ID}, plan.ReassignedPRs[0].NewReviewers)
+	assert.Equal(t, []models.UnresolvedPR{{
+		PRID:      "pr-1",
+		Reviewers: []string{f.leaving2},
+		Reason:    models.UnresolvedReasonNoLead,
+	}}, plan.UnresolvedPRs)
+}
+
+func TestBuildPlanAuthorTeamSkipsOtherAuthors(t *testing.T) {
+	f := newHandoverFixture()
+	spare := f.store.addUser(f.backend, 6, models.TeamRoleMember, true)
+	f.store.addPR("pr-2", f.frontendUser, f.leaving1)
+
+	plan, _, err := newTestService(f.store).buildPlan(context.Background(), uuids(f.leaving1), Options{AuthorTeam: "backend"})
+	require.NoError(t, err)
+
+	require.Len(t, plan.ReassignedPRs, 1)
+	assert.Equal(t, "pr-1", plan.ReassignedPRs[0].PRID)
+	assert.Equal(t, []string{spare}, plan.ReassignedPRs[0].NewReviewers)
+	assert.Equal(t, []models.UnresolvedPR{{
+		PRID:      "pr-2",
+		Reviewers: []string{f.leaving1},
+		Reason:    models.UnresolvedReasonOutsideTeam,
+	}}, plan.UnresolvedPRs)
+}
+
+func TestBuildPlanReportsUnknownAuthor(t *testing.T) {
+	f := newHandoverFixture()
+	f.store.addPR("pr-2", testID(99), f.leaving1)
+
+	plan := f.plan(t, Options{Fallback: FallbackCrossTeam})
+
+	assert.Contains(t, plan.UnresolvedPRs, models.UnresolvedPR{
+		PRID:      "pr-2",
+		Reviewers: []string{f.leaving1},
+		Reason:    models.UnresolvedReasonAuthorNotFound,
+	})
+}
+
+func TestPlanTokenIgnoresOrder(t *testing.T) {
+	f := newHandoverFixture()
+	s := newTestService(f.store)
+	f.store.addPR("pr-2", f.author, f.leaving1)
+	reassigned := []models.PRReassignment{
+		{PRID: "pr-1", Replaced: []string{f.leaving1}, NewReviewers: []string{f.frontendUser}},
+		{PRID: "pr-2", Replaced: []string{f.leaving1}, NewReviewers: []string{f.mobileUser}},
+	}
+	prs := f.store.prs
+
+	token, err := s.planToken(context.Background(), uuids(f.leaving1, f.leaving2), prs, reassigned, nil)
+	require.NoError(t, err)
+
+	reversed, err := s.planToken(context.Background(), uuids(f.leaving2, f.leaving1),
+		[]models.PullRequest{prs[1], prs[0]}, []models.PRReassignment{reassigned[1], reassigned[0]}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, token, reversed)
+}
+
+func TestPlanTokenChangesWithItsInputs(t *testing.T) {
+	f := newHandoverFixture()
+	ctx := context.Background()
+	deactivating := uuids(f.leaving1, f.leaving2)
+	reassigned := []models.PRReassignment{
+		{PRID: "pr-1", Replaced: []string{f.leaving1}, NewReviewers: []string{f.frontendUser}},
+	}
+	unresolved := []models.UnresolvedPR{
+		{PRID: "pr-1", Reviewers: []string{f.leaving2}, Reason: models.UnresolvedReasonNoCandidate},
+	}
+
+	token, err := newTestService(f.store).planToken(ctx, deactivating, f.store.prs, reassigned, unresolved)
+	require.NoError(t, err)
+
+	tokens := map[string]func() (string, error){
+		"users": func() (string, error) {
+			return newTestService(f.store).planToken(ctx, deactivating[:1], f.store.prs, reassigned, unresolved)
+		},
+		"pr reviewers": func() (string, error) {
+			prs := []models.PullRequest{f.store.prs[0]}
+			prs[0].Reviewers = []string{f.leaving1}
+			return newTestService(f.store).planToken(ctx, deactivating, prs, reassigned, unresolved)
+		},
+		"new reviewer": func() (string, error) {
+			changed := []models.PRReassignment{
+				{PRID: "pr-1", Replaced: []string{f.leaving1}, NewReviewers: []string{f.mobileUser}},
+			}
+			return newTestService(f.store).planToken(ctx, deactivating, f.store.prs, changed, unresolved)
+		},
+		"unresolved reason": func() (string, error) {
+			changed := []models.UnresolvedPR{
+				{PRID: "pr-1", Reviewers: []string{f.leaving2}, Reason: models.UnresolvedReasonNoLead},
+			}
+			return newTestService(f.store).planToken(ctx, deactivating, f.store.prs, reassigned, changed)
+		},
+		"new reviewer state": func() (string, error) {
+			f.store.users[f.frontendUser].IsActive = false
+			defer func() { f.store.users[f.frontendUser].IsActive = true }()
+			return newTestService(f.store).planToken(ctx, deactivating, f.store.prs, reassigned, unresolved)
+		},
+		"key": func() (string, error) {
+			s := New(f.store, f.store, f.store, nil, nil, nil, []byte("other-plan-key"))
+			return s.planToken(ctx, deactivating, f.store.prs, reassigned, unresolved)
+		},
+	}
+	for name, changed := range tokens {
+		t.Run(name, func(t *testing.T) {
+			other, err := changed()
+			require.NoError(t, err)
+			assert.NotEqual(t, token, other)
+		})
+	}
+}
+
+func TestPlanTokenRejectsInvalidReviewer(t *testing.T) {
+	f := newHandoverFixture()
+	reassigned := []models.PRReassignment{
+		{PRID: "pr-1", Replaced: []string{f.leaving1}, NewReviewers: []string{"not-a-uuid"}},
+	}
+
+	_, err := newTestService(f.store).planToken(context.Background(), uuids(f.leaving1), f.store.prs, reassigned, nil)
+	assert.ErrorIs(t, err, ErrInvalidPlan)
+}
+
+func TestCheckPlan(t *testing.T) {
+	f := newHandoverFixture()
+	inactive := f.store.addUser(f.store.teams["frontend"], 6, models.TeamRoleMember, false)
+	deactivating := uuids(f.leaving1, f.leaving2)
+
+	tests := []struct {
+		name string
+		item models.PRReassignment
+		ok   bool
+	}{
+		{"valid", models.PRReassignment{PRID: "pr-1", Replaced: []string{f.leaving1}, NewReviewers: []string{f.frontendUser}}, true},
+		{"removed", models.PRReassignment{PRID: "pr-1", Removed: []string{f.leaving1, f.leaving2}}, true},
+		{"unknown pr", models.PRReassignment{PRID: "pr-9", Replaced: []string{f.leaving1}, NewReviewers: []string{f.frontendUser}}, false},
+		{"replaced is not a reviewer", models.PRReassignment{PRID: "pr-1", Replaced: []string{f.frontendUser}, NewReviewers: []string{f.mobileUser}}, false},
+		{"removed is not leaving", models.PRReassignment{PRID: "pr-1", Removed: []string{f.author}}, false},
+		{"new reviewer is the author", models.PRReassignment{PRID: "pr-1", Replaced: []string{f.leaving1}, NewReviewers: []string{f.author}}, false},
+		{"new reviewer is leaving", models.PRReassignment{PRID: "pr-1", Replaced: []string{f.leaving1}, NewReviewers: []string{f.leaving2}}, false},
+		{"new reviewer twice", models.PRReassignment{PRID: "pr-1", Replaced: []string{f.leaving1, f.leaving2}, NewReviewers: []string{f.frontendUser, f.frontendUser}}, false},
+		{"new reviewer is inactive", models.PRReassignment{PRID: "pr-1", Replaced: []string{f.leaving1}, NewReviewers: []string{inactive}}, false},
+		{"new reviewer is unknown", models.PRReassignment{PRID: "pr-1", Replaced: []string{f.leaving1}, NewReviewers: []string{testID(99)}}, false},
+		{"new reviewer is not a uuid", models.PRReassignment{PRID: "pr-1", Replaced: []string{f.leaving1}, NewReviewers: []string{"u1"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newTestService(f.store).checkPlan(context.Background(), deactivating, f.store.prs, []models.PRReassignment{tt.item})
+			if tt.ok {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidPlan)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reviewer-service/internal/models"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownKind  = errors.New("unknown job kind")
+	ErrJobFinished  = errors.New("job already finished")
+	ErrInvalidJobID = errors.New("invalid job_id")
+)
+
+// progressEvery limits how often progress is written to the database.
+const progressEvery = 10
+
+type JobsRepository interface {
+	CreateJob(ctx context.Context, job models.Job) error
+	GetJob(ctx context.Context, id uuid.UUID) (*models.Job, error)
+	GetUnfinishedJobs(ctx context.Context) ([]models.Job, error)
+	StartJob(ctx context.Context, id uuid.UUID) error
+	UpdateProgress(ctx context.Context, id uuid.UUID, progress, total int) error
+	FinishJob(ctx context.Context, id uuid.UUID, status models.JobStatus, result []byte, errMsg string) error
+}
+
+// Handler executes a job of one kind; its result is stored as the job result.
+type Handler func(ctx context.Context, params []byte, progress func(done, total int)) (interface{}, error)
+
+// Service runs jobs in the background and resumes them after a restart.
+type Service struct {
+	repo JobsRepository
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+	running  map[string]context.CancelFunc
+}
+
+func New(repo JobsRepository) *Service {
+	return &Service{
+		repo:     repo,
+		handlers: map[string]Handler{},
+		running:  map[string]context.CancelFunc{},
+	}
+}
+
+func (s *Service) Register(kind string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[kind] = handler
+}
+
+// Submit stores a new job and starts it in the background.
+func (s *Service) Submit(ctx context.Context, kind string, params interface{}) (*models.Job, error) {
+	if s.handler(kind) == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKind, kind)
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("marshal params: %w", err)
+	}
+
+	now := time.Now()
+	job := models.Job{
+		ID:        uuid.New().String(),
+		Kind:      kind,
+		Status:    models.JobStatusPending,
+		Params:    data,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	s.start(job)
+	return &job, nil
+}
+
+func (s *Service) Get(ctx context.Context, id string) (*models.Job, error) {
+	jobID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidJobID
+	}
+	return s.repo.GetJob(ctx, jobID)
+}
+
+// Cancel stops a job. Work a running job has already committed stays in place.
+func (s *Service) Cancel(ctx context.Context, id string) (*models.Job, error) {
+	jobID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidJobID
+	}
+
+	job, err := s.repo.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Finished() {
+		return job, ErrJobFinished
+	}
+
+	s.mu.Lock()
+	cancel, ok := s.running[job.ID]
+	s.mu.Unlock()
+
+	if ok {
+		cancel()
+		return job, nil
+	}
+
+	if err := s.repo.FinishJob(ctx, jobID, models.JobStatusCancelled, nil, "cancelled"); err != nil {
+		return nil, err
+	}
+	return s.repo.GetJob(ctx, jobID)
+}
+
+// Resume restarts jobs left pending or running by a previous process.
+func (s *Service) Resume(ctx context.Context) error {
+	jobs, err := s.repo.GetUnfinishedJobs(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		log.Printf("Resuming job %s (%s)", job.ID, job.Kind)
+		s.start(job)
+	}
+	return nil
+}
+
+func (s *Service) handler(kind string) Handler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.handlers[kind]
+}
+
+func (s *Service) start(job models.Job) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s.mu.Lock()
+	s.running[job.ID] = cancel
+	s.mu.Unlock()
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.running, job.ID)
+			s.mu.Unlock()
+			cancel()
+		}()
+		s.run(ctx, job)
+	}()
+}
+
+func (s *Service) run(ctx context.Context, job models.Job) {
+	jobID := uuid.MustParse(job.ID)
+
+	handler := s.handler(job.Kind)
+	if handler == nil {
+		s.finish(jobID, models.JobStatusFailed, nil, ErrUnknownKind.Error())
+		return
+	}
+
+	if err := s.repo.StartJob(ctx, jobID); err != nil {
+		log.Printf("job %s: start: %v", job.ID, err)
+	}
+
+	progress := func(done, total int) {
+		if done%progressEvery != 0 && done != total {
+			return
+		}
+		if err := s.repo.UpdateProgress(ctx, jobID, done, total); err != nil && ctx.Err() == nil {
+			log.Printf("job %s: update progress: %v", job.ID, err)
+		}
+	}
+
+	result, err := handler(ctx, job.Params, progress)
+	switch {
+	case err != nil && ctx.Err() != nil:
+		s.finish(jobID, models.JobStatusCancelled, nil, "cancelled")
+	case err != nil:
+		s.finish(jobID, models.JobStatusFailed, nil, err.Error())
+	default:
+		data, err := json.Marshal(result)
+		if err != nil {
+			s.finish(jobID, models.JobStatusFailed, nil, fmt.Sprintf("marshal result: %v", err))
+			return
+		}
+		s.finish(jobID, models.JobStatusSucceeded, data, "")
+	}
+}
+
+func (s *Service) finish(jobID uuid.UUID, status models.JobStatus, result []byte, errMsg string) {
+	if err := s.repo.FinishJob(context.Background(), jobID, status, result, errMsg); err != nil {
+		log.Printf("job %s: finish: %v", jobID, err)
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
+	"reviewer-service/internal/metrics"
 	"reviewer-service/internal/models"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,25 +17,43 @@ type PullRequestsRepository interface {
 	CreatePullRequest(ctx context.Context, pr models.PullRequest) error
 	GetPullRequestByID(ctx context.Context, id string) (*models.PullRequest, error)
 	MergePullRequest(ctx context.Context, id string) error
-	AssignReviewer(ctx context.Context, prID, reviewerID string, orderIndex int) error
 	ReassignReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) error
 	GetOpenPRsWithInactiveReviewers(ctx context.Context, inactiveUserIDs []uuid.UUID) ([]models.PullRequest, error)
 	BulkReassignReviewers(ctx context.Context, reassignments []models.ReviewerReassignment) error
+	GetOpenReviewCounts(ctx context.Context, userIDs []uuid.UUID) (map[string]int, error)
+	SetReviewVerdict(ctx context.Context, prID string, reviewerID uuid.UUID, verdict models.ReviewVerdict) (*models.Review, error)
 }
 
+var ErrInvalidVerdict = errors.New("verdict must be PENDING, APPROVED or CHANGES_REQUESTED")
+
 type UsersRepository interface {
 	GetUserByID(ctx context.Context, id string) (*models.User, error)
 }
 
+type TeamsRepository interface {
+	GetTeamByID(ctx context.Context, id uuid.UUID) (*models.Team, error)
+	GetEscalationLeads(ctx context.Context, id uuid.UUID) ([]*models.User, error)
+}
+
+type SettingsRepository interface {
+	GetTeamSettings(ctx context.Context, teamID uuid.UUID) (*models.TeamSettingsVersion, error)
+}
+
 type Service struct {
-	prRepo    PullRequestsRepository
-	usersRepo UsersRepository
+	prRepo       PullRequestsRepository
+	usersRepo    UsersRepository
+	teamsRepo    TeamsRepository
+	settingsRepo SettingsRepository
+	metrics      *metrics.Registry
 }
 
-func New(prRepo PullRequestsRepository, usersRepo UsersRepository) *Service {
+func New(prRepo PullRequestsRepository, usersRepo UsersRepository, teamsRepo TeamsRepository, settingsRepo SettingsRepository, registry *metrics.Registry) *Service {
 	return &Service{
-		prRepo:    prRepo,
-		usersRepo: usersRepo,
+		prRepo:       prRepo,
+		usersRepo:    usersRepo,
+		teamsRepo:    teamsRepo,
+		settingsRepo: settingsRepo,
+		metrics:      registry,
 	}
 }
 
@@ -51,28 +72,6 @@ func (s *Service) CreatePR(ctx context.Context, authorID, name string) (string,
 	return pr.ID, nil
 }
 
-func (s *Service) AssignReviewers(ctx context.Context, prID string, reviewers []string) error {
-	if len(reviewers) == 0 || len(reviewers) > 2 {
-		return errors.New("reviewers count must be 1 or 2")
-	}
-
-	for i, r := range reviewers {
-		user, err := s.usersRepo.GetUserByID(ctx, r)
-		if err != nil {
-			return err
-		}
-		if !user.IsActive {
-			return errors.New("reviewer is not active")
-		}
-
-		if err := s.prRepo.AssignReviewer(ctx, prID, r, i+1); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 func (s *Service) Merge(ctx context.Context, prID string) error {
 	return s.prRepo.MergePullRequest(ctx, prID)
 }
@@ -89,11 +88,44 @@ func (s *Service) ReassignReviewer(ctx context.Context, prID, oldID, newID strin
 		return errors.New("new reviewer not active")
 	}
 
-	return s.prRepo.ReassignReviewer(ctx, prID, oldID, newID)
+	if err := s.prRepo.ReassignReviewer(ctx, prID, oldID, newID); err != nil {
+		return err
+	}
+	s.metrics.AddReassignments(1)
+	return nil
 }
 
+// SetVerdict returns nil when the reviewer is not assigned to the PR.
+func (s *Service) SetVerdict(ctx context.Context, prID string, reviewerID uuid.UUID, verdict models.ReviewVerdict) (*models.Review, error) {
+	switch verdict {
+	case models.ReviewVerdictPending, models.ReviewVerdictApproved, models.ReviewVerdictChangesRequested:
+	default:
+		return nil, ErrInvalidVerdict
+	}
+	return s.prRepo.SetReviewVerdict(ctx, prID, reviewerID, verdict)
+}
+
+// CreatePullRequest stores the PR together with its reviewers.
 func (s *Service) CreatePullRequest(ctx context.Context, pr models.PullRequest) error {
-	return s.prRepo.CreatePullRequest(ctx, pr)
+	if len(pr.Reviewers) > models.MaxReviewers {
+		return fmt.Errorf("reviewers count must be at most %d", models.MaxReviewers)
+	}
+
+	for _, r := range pr.Reviewers {
+		user, err := s.usersRepo.GetUserByID(ctx, r)
+		if err != nil {
+			return err
+		}
+		if !user.IsActive {
+			return errors.New("reviewer is not active")
+		}
+	}
+
+	if err := s.prRepo.CreatePullRequest(ctx, pr); err != nil {
+		return err
+	}
+	s.metrics.AddAssignments(len(pr.Reviewers))
+	return nil
 }
 
 func (s *Service) GetPullRequest(ctx context.Context, prID string) (*models.PullRequest, error) {
@@ -115,3 +147,161 @@ func (s *Service) GetOpenPRsWithInactiveReviewers(ctx context.Context, inactiveU
 func (s *Service) BulkReassignReviewers(ctx context.Context, reassignments []models.ReviewerReassignment) error {
 	return s.prRepo.BulkReassignReviewers(ctx, reassignments)
 }
+
+// PickReviewers chooses the reviewers of a new PR by the team settings.
+func (s *Service) PickReviewers(ctx context.Context, team *models.Team, authorID string, labels []string) ([]string, error) {
+	if team.Archived() {
+		return []string{}, nil
+	}
+
+	settings, err := s.teamSettings(ctx, team)
+	if err != nil {
+		return nil, err
+	}
+
+	excludeIDs := map[string]bool{authorID: true}
+	reviewers := []string{}
+
+	lead, err := s.escalationLead(ctx, team, excludeIDs)
+	if err != nil {
+		return nil, err
+	}
+	if lead != "" && team.RequiresLeadReview(labels) {
+		reviewers = append(reviewers, lead)
+		excludeIDs[lead] = true
+	}
+
+	picked, err := s.pick(ctx, team, settings, excludeIDs, settings.ReviewerCount-len(reviewers))
+	if err != nil {
+		return nil, err
+	}
+	reviewers = append(reviewers, picked...)
+
+	if len(reviewers) == 0 && lead != "" {
+		reviewers = append(reviewers, lead)
+	}
+	return reviewers, nil
+}
+
+func (s *Service) PickReplacement(ctx context.Context, team *models.Team, excludeIDs map[string]bool) (string, error) {
+	id, err := s.pickReplacement(ctx, team, excludeIDs)
+	if err == nil && id == "" {
+		s.metrics.AddNoCandidates(1)
+	}
+	return id, err
+}
+
+func (s *Service) pickReplacement(ctx context.Context, team *models.Team, excludeIDs map[string]bool) (string, error) {
+	if team.Archived() {
+		return "", nil
+	}
+
+	settings, err := s.teamSettings(ctx, team)
+	if err != nil {
+		return "", err
+	}
+
+	picked, err := s.pick(ctx, team, settings, excludeIDs, 1)
+	if err != nil {
+		return "", err
+	}
+	if len(picked) > 0 {
+		return picked[0], nil
+	}
+
+	return s.escalationLead(ctx, team, excludeIDs)
+}
+
+// pick adds the picked ids to excludeIDs.
+func (s *Service) pick(ctx context.Context, team *models.Team, settings models.TeamSettings, excludeIDs map[string]bool, count int) ([]string, error) {
+	teams := []*models.Team{team}
+	for _, id := range settings.FallbackTeamIDs {
+		teamID, err := uuid.Parse(id)
+		if err != nil {
+			continue
+		}
+		fallback, err := s.teamsRepo.GetTeamByID(ctx, teamID)
+		if err != nil {
+			continue
+		}
+		teams = append(teams, fallback)
+	}
+
+	picked := []string{}
+	for _, t := range teams {
+		if len(picked) >= count {
+			break
+		}
+
+		var candidates []string
+		for _, member := range t.Reviewers() {
+			if member.IsActive && !excludeIDs[member.ID] {
+				candidates = append(candidates, member.ID)
+			}
+		}
+
+		if err := s.order(ctx, candidates, settings.SelectionStrategy); err != nil {
+			return nil, err
+		}
+
+		for _, id := range candidates {
+			if len(picked) >= count {
+				break
+			}
+			picked = append(picked, id)
+			excludeIDs[id] = true
+		}
+	}
+	return picked, nil
+}
+
+func (s *Service) order(ctx context.Context, candidates []string, strategy models.SelectionStrategy) error {
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	if strategy != models.SelectionLeastLoaded || len(candidates) < 2 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(candidates))
+	for _, id := range candidates {
+		uid, err := uuid.Parse(id)
+		if err != nil {
+			return fmt.Errorf("invalid user_id %s: %w", id, err)
+		}
+		ids = append(ids, uid)
+	}
+
+	load, err := s.prRepo.GetOpenReviewCounts(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("get review load: %w", err)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return load[candidates[i]] < load[candidates[j]]
+	})
+	return nil
+}
+
+func (s *Service) escalationLead(ctx context.Context, team *models.Team, excludeIDs map[string]bool) (string, error) {
+	leads, err := s.teamsRepo.GetEscalationLeads(ctx, uuid.MustParse(team.ID))
+	if err != nil {
+		return "", fmt.Errorf("get leads: %w", err)
+	}
+	for _, lead := range leads {
+		if !excludeIDs[lead.ID] {
+			return lead.ID, nil
+		}
+	}
+	return "", nil
+}
+
+func (s *Service) teamSettings(ctx context.Context, team *models.Team) (models.TeamSettings, error) {
+	v, err := s.settingsRepo.GetTeamSettings(ctx, uuid.MustParse(team.ID))
+	if err != nil {
+		return models.TeamSettings{}, err
+	}
+	if v == nil {
+		return models.DefaultTeamSettings(), nil
+	}
+	return v.Settings, nil
+}
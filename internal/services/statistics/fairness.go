@@ -0,0 +1,136 @@
+package statistics
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reviewer-service/internal/storage"
+	"sort"
+)
+
+// DefaultFairnessThreshold is in standard deviations from the team mean.
+const DefaultFairnessThreshold = 1.0
+
+var ErrInvalidThreshold = errors.New("threshold must be a positive number")
+
+// MemberFairness is a member's assignments per active day.
+type MemberFairness struct {
+	UserID      string
+	Username    string
+	Assignments int
+	ActiveDays  float64
+	Rate        float64
+	Deviation   float64
+}
+
+// TeamFairness measures how evenly a team's reviews were spread in a window.
+// MaxMinRatio is nil when the least loaded member got nothing.
+type TeamFairness struct {
+	TeamName    string
+	Members     []MemberFairness
+	Excluded    []string
+	Mean        float64
+	StdDev      float64
+	Gini        float64
+	MaxMinRatio *float64
+	Above       []string
+	Below       []string
+}
+
+func (s *Service) GetFairness(ctx context.Context, teamName string, window storage.Window, threshold float64) ([]TeamFairness, error) {
+	if !(threshold > 0) || math.IsInf(threshold, 1) {
+		return nil, ErrInvalidThreshold
+	}
+	if teamName != "" {
+		if _, err := s.teamsRepo.GetTeamByName(ctx, teamName); err != nil {
+			return nil, err
+		}
+	}
+
+	loads, err := s.repo.GetMemberLoads(ctx, teamName, window)
+	if err != nil {
+		return nil, err
+	}
+
+	var teams []TeamFairness
+	for _, load := range loads {
+		if len(teams) == 0 || teams[len(teams)-1].TeamName != load.TeamName {
+			teams = append(teams, TeamFairness{
+				TeamName: load.TeamName,
+				Members:  []MemberFairness{},
+				Excluded: []string{},
+				Above:    []string{},
+				Below:    []string{},
+			})
+		}
+		team := &teams[len(teams)-1]
+
+		if load.ActiveDays <= 0 {
+			team.Excluded = append(team.Excluded, load.UserID)
+			continue
+		}
+		team.Members = append(team.Members, MemberFairness{
+			UserID:      load.UserID,
+			Username:    load.Username,
+			Assignments: load.Assignments,
+			ActiveDays:  load.ActiveDays,
+			Rate:        float64(load.Assignments) / load.ActiveDays,
+		})
+	}
+
+	if teams == nil {
+		return []TeamFairness{}, nil
+	}
+	for i := range teams {
+		teams[i].measure(threshold)
+	}
+	return teams, nil
+}
+
+func (t *TeamFairness) measure(threshold float64) {
+	n := float64(len(t.Members))
+	if n == 0 {
+		return
+	}
+
+	rates := make([]float64, 0, len(t.Members))
+	var sum float64
+	for _, m := range t.Members {
+		rates = append(rates, m.Rate)
+		sum += m.Rate
+	}
+	t.Mean = sum / n
+
+	var squares float64
+	for _, rate := range rates {
+		squares += (rate - t.Mean) * (rate - t.Mean)
+	}
+	t.StdDev = math.Sqrt(squares / n)
+
+	sort.Float64s(rates)
+	if sum > 0 {
+		var weighted float64
+		for i, rate := range rates {
+			weighted += float64(i+1) * rate
+		}
+		t.Gini = 2*weighted/(n*sum) - (n+1)/n
+	}
+	if lowest := rates[0]; lowest > 0 {
+		ratio := rates[len(rates)-1] / lowest
+		t.MaxMinRatio = &ratio
+	}
+
+	if t.StdDev == 0 {
+		return
+	}
+	for i := range t.Members {
+		m := &t.Members[i]
+		m.Deviation = (m.Rate - t.Mean) / t.StdDev
+		switch {
+		case m.Deviation > threshold:
+			t.Above = append(t.Above, m.UserID)
+		case m.Deviation < -threshold:
+			t.Below = append(t.Below, m.UserID)
+		}
+	}
+}
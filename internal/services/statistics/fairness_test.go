@@ -0,0 +1,104 @@
+package statistics
+
+import (
+	"context"
+	"math"
+	"reviewer-service/internal/models"
+	"reviewer-service/internal/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fairnessOf(rates ...float64) *TeamFairness {
+	team := &TeamFairness{}
+	for i, rate := range rates {
+		team.Members = append(team.Members, MemberFairness{UserID: string(rune('a' + i)), Rate: rate})
+	}
+	return team
+}
+
+func TestMeasureUnevenTeam(t *testing.T) {
+	team := fairnessOf(1, 1, 4)
+	team.measure(DefaultFairnessThreshold)
+
+	assert.InDelta(t, 2, team.Mean, 1e-9)
+	assert.InDelta(t, math.Sqrt2, team.StdDev, 1e-9)
+	assert.InDelta(t, 1.0/3, team.Gini, 1e-9)
+	require.NotNil(t, team.MaxMinRatio)
+	assert.InDelta(t, 4, *team.MaxMinRatio, 1e-9)
+	assert.InDelta(t, math.Sqrt2, team.Members[2].Deviation, 1e-9)
+	assert.Equal(t, []string{"c"}, team.Above)
+	assert.Empty(t, team.Below)
+}
+
+func TestMeasureEvenTeam(t *testing.T) {
+	team := fairnessOf(3, 3, 3)
+	team.measure(DefaultFairnessThreshold)
+
+	assert.Zero(t, team.Gini)
+	assert.Zero(t, team.StdDev)
+	require.NotNil(t, team.MaxMinRatio)
+	assert.InDelta(t, 1, *team.MaxMinRatio, 1e-9)
+	assert.Empty(t, team.Above)
+	assert.Empty(t, team.Below)
+}
+
+func TestMeasureIdleMember(t *testing.T) {
+	team := fairnessOf(0, 2)
+	team.measure(0.5)
+
+	assert.InDelta(t, 0.5, team.Gini, 1e-9)
+	assert.Nil(t, team.MaxMinRatio)
+	assert.Equal(t, []string{"b"}, team.Above)
+	assert.Equal(t, []string{"a"}, team.Below)
+
+	idle := fairnessOf(0, 0)
+	idle.measure(DefaultFairnessThreshold)
+	assert.Zero(t, idle.Gini)
+	assert.Nil(t, idle.MaxMinRatio)
+}
+
+func TestGetFairnessGroupsTeamsAndExcludesInactiveMembers(t *testing.T) {
+	repo := &fakeStatisticsRepo{loads: []storage.MemberLoad{
+		{TeamName: "backend", UserID: "u1", Assignments: 4, ActiveDays: 2},
+		{TeamName: "backend", UserID: "u2", Assignments: 0, ActiveDays: 0},
+		{TeamName: "backend", UserID: "u3", Assignments: 3, ActiveDays: 1.5},
+		{TeamName: "frontend", UserID: "u4", Assignments: 1, ActiveDays: 4},
+	}}
+	s := New(repo, &fakeTeamsRepo{})
+
+	teams, err := s.GetFairness(context.Background(), "", storage.Window{}, DefaultFairnessThreshold)
+	require.NoError(t, err)
+
+	require.Len(t, teams, 2)
+	assert.Equal(t, "backend", teams[0].TeamName)
+	require.Len(t, teams[0].Members, 2)
+	assert.InDelta(t, 2, teams[0].Members[0].Rate, 1e-9)
+	assert.InDelta(t, 2, teams[0].Members[1].Rate, 1e-9)
+	assert.Equal(t, []string{"u2"}, teams[0].Excluded)
+	assert.Equal(t, "frontend", teams[1].TeamName)
+	assert.InDelta(t, 0.25, teams[1].Members[0].Rate, 1e-9)
+}
+
+func TestGetFairnessWithoutLoads(t *testing.T) {
+	s := New(&fakeStatisticsRepo{}, &fakeTeamsRepo{})
+
+	teams, err := s.GetFairness(context.Background(), "", storage.Window{}, DefaultFairnessThreshold)
+	require.NoError(t, err)
+	assert.NotNil(t, teams)
+	assert.Empty(t, teams)
+}
+
+func TestGetFairnessRejectsInvalidInput(t *testing.T) {
+	s := New(&fakeStatisticsRepo{}, &fakeTeamsRepo{teams: map[string]*models.Team{}})
+
+	for _, threshold := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		_, err := s.GetFairness(context.Background(), "", storage.Window{}, threshold)
+		assert.ErrorIs(t, err, ErrInvalidThreshold, "threshold %v", threshold)
+	}
+
+	_, err := s.GetFairness(context.Background(), "missing", storage.Window{}, DefaultFairnessThreshold)
+	assert.ErrorIs(t, err, models.ErrTeamNotFound)
+}
@@ -0,0 +1,78 @@
+package statistics
+
+import (
+	"context"
+	"reviewer-service/internal/storage"
+	"sort"
+)
+
+type InteractionUser struct {
+	UserID   string
+	Username string
+}
+
+// TeamInteractions is the author to reviewer matrix of a team over a window:
+// Reviews[i][j] counts the reviews Users[j] got on PRs of Users[i].
+type TeamInteractions struct {
+	TeamName string
+	Users    []InteractionUser
+	Reviews  [][]int
+}
+
+func (s *Service) GetInteractions(ctx context.Context, teamName string, window storage.Window) ([]TeamInteractions, error) {
+	if teamName != "" {
+		if _, err := s.teamsRepo.GetTeamByName(ctx, teamName); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := s.repo.GetInteractions(ctx, teamName, window)
+	if err != nil {
+		return nil, err
+	}
+
+	var teams []TeamInteractions
+	for start := 0; start < len(rows); {
+		end := start
+		for end < len(rows) && rows[end].TeamName == rows[start].TeamName {
+			end++
+		}
+		teams = append(teams, newTeamInteractions(rows[start:end]))
+		start = end
+	}
+	if teams == nil && teamName != "" {
+		teams = append(teams, TeamInteractions{TeamName: teamName, Users: []InteractionUser{}, Reviews: [][]int{}})
+	}
+	return teams, nil
+}
+
+func newTeamInteractions(rows []storage.Interaction) TeamInteractions {
+	names := map[string]string{}
+	for _, row := range rows {
+		names[row.AuthorID] = row.AuthorName
+		names[row.ReviewerID] = row.ReviewerName
+	}
+
+	users := make([]InteractionUser, 0, len(names))
+	for id, name := range names {
+		users = append(users, InteractionUser{UserID: id, Username: name})
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].Username != users[j].Username {
+			return users[i].Username < users[j].Username
+		}
+		return users[i].UserID < users[j].UserID
+	})
+
+	index := make(map[string]int, len(users))
+	reviews := make([][]int, len(users))
+	for i, user := range users {
+		index[user.UserID] = i
+		reviews[i] = make([]int, len(users))
+	}
+	for _, row := range rows {
+		reviews[index[row.AuthorID]][index[row.ReviewerID]] = row.Reviews
+	}
+
+	return TeamInteractions{TeamName: rows[0].TeamName, Users: users, Reviews: reviews}
+}
@@ -0,0 +1,83 @@
+package statistics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reviewer-service/internal/storage"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Bucket is the size of the buckets of a time series.
+type Bucket string
+
+const (
+	BucketDay   Bucket = "day"
+	BucketWeek  Bucket = "week"
+	BucketMonth Bucket = "month"
+)
+
+// MaxSeriesPoints caps the number of buckets of one series.
+const MaxSeriesPoints = 1000
+
+var ErrInvalidSeries = errors.New("invalid series")
+
+// SeriesRequest selects a time series.
+type SeriesRequest struct {
+	Window   storage.Window
+	Bucket   Bucket
+	TimeZone string
+	TeamName string
+	UserID   string
+}
+
+// GetSeries returns every bucket of the window, empty buckets included.
+func (s *Service) GetSeries(ctx context.Context, req SeriesRequest) ([]storage.SeriesPoint, error) {
+	var approx time.Duration
+	switch req.Bucket {
+	case BucketDay:
+		approx = 24 * time.Hour
+	case BucketWeek:
+		approx = 7 * 24 * time.Hour
+	case BucketMonth:
+		approx = 28 * 24 * time.Hour
+	default:
+		return nil, fmt.Errorf("%w: bucket must be day, week or month", ErrInvalidSeries)
+	}
+	if req.Window.To.Sub(req.Window.From)/approx >= MaxSeriesPoints {
+		return nil, fmt.Errorf("%w: more than %d buckets", ErrInvalidSeries, MaxSeriesPoints)
+	}
+
+	if req.TimeZone == "" {
+		req.TimeZone = "UTC"
+	}
+	loc, err := time.LoadLocation(req.TimeZone)
+	if err != nil || loc == time.Local {
+		return nil, fmt.Errorf("%w: unknown time zone %s", ErrInvalidSeries, req.TimeZone)
+	}
+
+	filter := storage.SeriesFilter{TeamName: req.TeamName}
+	if req.UserID != "" {
+		id, err := uuid.Parse(req.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid user_id %s", ErrInvalidSeries, req.UserID)
+		}
+		filter.UserID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+	if req.TeamName != "" {
+		if _, err := s.teamsRepo.GetTeamByName(ctx, req.TeamName); err != nil {
+			return nil, err
+		}
+	}
+
+	points, err := s.repo.GetSeries(ctx, req.Window, string(req.Bucket), req.TimeZone, filter)
+	if err != nil {
+		return nil, err
+	}
+	for i := range points {
+		points[i].Start = points[i].Start.In(loc)
+	}
+	return points, nil
+}
@@ -0,0 +1,92 @@
+package statistics
+
+import (
+	"context"
+	"reviewer-service/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seriesWindow(d time.Duration) storage.Window {
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	return storage.Window{From: from, To: from.Add(d)}
+}
+
+func TestGetSeriesBucketLimit(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		bucket Bucket
+		window time.Duration
+		ok     bool
+	}{
+		{BucketDay, (MaxSeriesPoints - 1) * day, true},
+		{BucketDay, MaxSeriesPoints * day, false},
+		{BucketWeek, (MaxSeriesPoints - 1) * 7 * day, true},
+		{BucketWeek, MaxSeriesPoints * 7 * day, false},
+		{BucketMonth, (MaxSeriesPoints - 1) * 28 * day, true},
+		{BucketMonth, MaxSeriesPoints * 28 * day, false},
+	}
+	for _, tt := range tests {
+		s := New(&fakeStatisticsRepo{}, &fakeTeamsRepo{})
+		_, err := s.GetSeries(context.Background(), SeriesRequest{
+			Window:   seriesWindow(tt.window),
+			Bucket:   tt.bucket,
+			TimeZone: "UTC",
+		})
+		if tt.ok {
+			assert.NoError(t, err, "%s over %s", tt.bucket, tt.window)
+		} else {
+			assert.ErrorIs(t, err, ErrInvalidSeries, "%s over %s", tt.bucket, tt.window)
+		}
+	}
+}
+
+func TestGetSeriesRejectsInvalidRequests(t *testing.T) {
+	tests := map[string]SeriesRequest{
+		"bucket":       {Bucket: "hour", TimeZone: "UTC"},
+		"time zone":    {Bucket: BucketDay, TimeZone: "Mars/Olympus"},
+		"local zone":   {Bucket: BucketDay, TimeZone: "Local"},
+		"user_id":      {Bucket: BucketDay, TimeZone: "UTC", UserID: "u1"},
+		"empty bucket": {TimeZone: "UTC"},
+	}
+	for name, req := range tests {
+		t.Run(name, func(t *testing.T) {
+			req.Window = seriesWindow(7 * 24 * time.Hour)
+			_, err := New(&fakeStatisticsRepo{}, &fakeTeamsRepo{}).GetSeries(context.Background(), req)
+			assert.ErrorIs(t, err, ErrInvalidSeries)
+		})
+	}
+}
+
+func TestGetSeriesReturnsPointsInTheTimeZone(t *testing.T) {
+	start := time.Date(2025, 3, 1, 21, 0, 0, 0, time.UTC)
+	repo := &fakeStatisticsRepo{points: []storage.SeriesPoint{{Start: start, OpenedPRs: 2}}}
+	s := New(repo, &fakeTeamsRepo{})
+
+	points, err := s.GetSeries(context.Background(), SeriesRequest{
+		Window:   seriesWindow(7 * 24 * time.Hour),
+		Bucket:   BucketDay,
+		TimeZone: "Europe/Moscow",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Europe/Moscow", repo.seriesTimeZone)
+	require.Len(t, points, 1)
+	assert.True(t, start.Equal(points[0].Start))
+	assert.Equal(t, "Europe/Moscow", points[0].Start.Location().String())
+	assert.Equal(t, 0, points[0].Start.Hour())
+}
+
+func TestGetSeriesDefaultsToUTC(t *testing.T) {
+	repo := &fakeStatisticsRepo{}
+
+	_, err := New(repo, &fakeTeamsRepo{}).GetSeries(context.Background(), SeriesRequest{
+		Window: seriesWindow(24 * time.Hour),
+		Bucket: BucketDay,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", repo.seriesTimeZone)
+}
@@ -2,27 +2,133 @@ package statistics
 
 import (
 	"context"
+	"errors"
+	"reviewer-service/internal/models"
 	"reviewer-service/internal/storage"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type StatisticsRepository interface {
-	GetUserAssignmentStats(ctx context.Context) ([]storage.UserAssignmentStats, error)
-	GetPRStats(ctx context.Context) (*storage.PRStats, error)
+	GetUserAssignmentStats(ctx context.Context, teamIDs []uuid.UUID, window storage.Window) ([]storage.UserAssignmentStats, error)
+	GetPRStats(ctx context.Context, teamIDs []uuid.UUID, window storage.Window) (*storage.PRStats, error)
+	GetTeamStats(ctx context.Context, teamName string, window storage.Window) ([]storage.TeamStats, error)
+	GetLatencyStats(ctx context.Context, teamName string, window storage.Window) (*storage.LatencyStats, error)
+	GetTeamLatencyStats(ctx context.Context, teamName string, window storage.Window) ([]storage.TeamLatency, error)
+	GetReviewerLatencyStats(ctx context.Context, teamName string, window storage.Window) ([]storage.ReviewerLatency, error)
+	GetMemberLoads(ctx context.Context, teamName string, window storage.Window) ([]storage.MemberLoad, error)
+	GetSeries(ctx context.Context, window storage.Window, bucket, timeZone string, filter storage.SeriesFilter) ([]storage.SeriesPoint, error)
+	GetInteractions(ctx context.Context, teamName string, window storage.Window) ([]storage.Interaction, error)
+}
+
+// DefaultWindow is how far back statistics reach when no start is given.
+const DefaultWindow = 30 * 24 * time.Hour
+
+var ErrInvalidWindow = errors.New("from must be before to")
+
+type TeamsRepository interface {
+	GetTeamByName(ctx context.Context, name string) (*models.Team, error)
+	GetSubtreeTeamIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+}
+
+// Scope limits statistics to a team; the zero Scope covers all teams.
+type Scope struct {
+	TeamName        string
+	IncludeSubteams bool
+}
+
+// NewWindow replaces a zero to with now and a zero from with DefaultWindow before to.
+func NewWindow(from, to, now time.Time) (storage.Window, error) {
+	if to.IsZero() {
+		to = now
+	}
+	if from.IsZero() {
+		from = to.Add(-DefaultWindow)
+	}
+	if !from.Before(to) {
+		return storage.Window{}, ErrInvalidWindow
+	}
+	return storage.Window{From: from, To: to}, nil
+}
+
+type Latency struct {
+	Overall   storage.LatencyStats
+	Teams     []storage.TeamLatency
+	Reviewers []storage.ReviewerLatency
 }
 
 type Service struct {
-	repo StatisticsRepository
+	repo      StatisticsRepository
+	teamsRepo TeamsRepository
+}
+
+func New(repo StatisticsRepository, teamsRepo TeamsRepository) *Service {
+	return &Service{repo: repo, teamsRepo: teamsRepo}
+}
+
+func (s *Service) GetUserAssignmentStats(ctx context.Context, scope Scope, window storage.Window) ([]storage.UserAssignmentStats, error) {
+	teamIDs, err := s.teamIDs(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetUserAssignmentStats(ctx, teamIDs, window)
 }
 
-func New(repo StatisticsRepository) *Service {
-	return &Service{repo: repo}
+func (s *Service) GetPRStats(ctx context.Context, scope Scope, window storage.Window) (*storage.PRStats, error) {
+	teamIDs, err := s.teamIDs(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetPRStats(ctx, teamIDs, window)
 }
 
-func (s *Service) GetUserAssignmentStats(ctx context.Context) ([]storage.UserAssignmentStats, error) {
-	return s.repo.GetUserAssignmentStats(ctx)
+func (s *Service) GetTeamStats(ctx context.Context, teamName string, window storage.Window) ([]storage.TeamStats, error) {
+	if teamName != "" {
+		if _, err := s.teamsRepo.GetTeamByName(ctx, teamName); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.GetTeamStats(ctx, teamName, window)
 }
 
-func (s *Service) GetPRStats(ctx context.Context) (*storage.PRStats, error) {
-	return s.repo.GetPRStats(ctx)
+// GetLatency returns the review latency percentiles of the window.
+func (s *Service) GetLatency(ctx context.Context, teamName string, window storage.Window) (*Latency, error) {
+	if teamName != "" {
+		if _, err := s.teamsRepo.GetTeamByName(ctx, teamName); err != nil {
+			return nil, err
+		}
+	}
+
+	overall, err := s.repo.GetLatencyStats(ctx, teamName, window)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := s.repo.GetTeamLatencyStats(ctx, teamName, window)
+	if err != nil {
+		return nil, err
+	}
+	reviewers, err := s.repo.GetReviewerLatencyStats(ctx, teamName, window)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Latency{Overall: *overall, Teams: teams, Reviewers: reviewers}, nil
 }
 
+func (s *Service) teamIDs(ctx context.Context, scope Scope) ([]uuid.UUID, error) {
+	if scope.TeamName == "" {
+		return nil, nil
+	}
+
+	team, err := s.teamsRepo.GetTeamByName(ctx, scope.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	teamID := uuid.MustParse(team.ID)
+
+	if !scope.IncludeSubteams {
+		return []uuid.UUID{teamID}, nil
+	}
+	return s.teamsRepo.GetSubtreeTeamIDs(ctx, teamID)
+}
@@ -0,0 +1,85 @@
+package statistics
+
+import (
+	"context"
+	"reviewer-service/internal/models"
+	"reviewer-service/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStatisticsRepo serves canned rows; the embedded interface is nil, so
+// methods a test does not expect to be called panic.
+type fakeStatisticsRepo struct {
+	StatisticsRepository
+	loads  []storage.MemberLoad
+	points []storage.SeriesPoint
+
+	// seriesTimeZone records the time zone the last series was asked in.
+	seriesTimeZone string
+}
+
+func (r *fakeStatisticsRepo) GetMemberLoads(ctx context.Context, teamName string, window storage.Window) ([]storage.MemberLoad, error) {
+	return r.loads, nil
+}
+
+func (r *fakeStatisticsRepo) GetSeries(ctx context.Context, window storage.Window, bucket, timeZone string, filter storage.SeriesFilter) ([]storage.SeriesPoint, error) {
+	r.seriesTimeZone = timeZone
+	return r.points, nil
+}
+
+type fakeTeamsRepo struct {
+	TeamsRepository
+	teams map[string]*models.Team
+}
+
+func (r *fakeTeamsRepo) GetTeamByName(ctx context.Context, name string) (*models.Team, error) {
+	team, ok := r.teams[name]
+	if !ok {
+		return nil, models.ErrTeamNotFound
+	}
+	return team, nil
+}
+
+func TestNewWindow(t *testing.T) {
+	now := time.Date(2025, 11, 20, 12, 0, 0, 0, time.UTC)
+	from := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		wantFrom time.Time
+		wantTo   time.Time
+	}{
+		{"defaults to the last 30 days", time.Time{}, time.Time{}, now.Add(-DefaultWindow), now},
+		{"from only ends now", from, time.Time{}, from, now},
+		{"to only starts 30 days earlier", time.Time{}, to, to.Add(-DefaultWindow), to},
+		{"both given", from, to, from, to},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, err := NewWindow(tt.from, tt.to, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFrom, window.From)
+			assert.Equal(t, tt.wantTo, window.To)
+		})
+	}
+}
+
+func TestNewWindowRejectsEmptyAndReversedWindows(t *testing.T) {
+	now := time.Date(2025, 11, 20, 12, 0, 0, 0, time.UTC)
+	day := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := NewWindow(day, day, now)
+	assert.ErrorIs(t, err, ErrInvalidWindow)
+
+	_, err = NewWindow(day.AddDate(0, 0, 1), day, now)
+	assert.ErrorIs(t, err, ErrInvalidWindow)
+
+	_, err = NewWindow(now.Add(time.Hour), time.Time{}, now)
+	assert.ErrorIs(t, err, ErrInvalidWindow)
+}
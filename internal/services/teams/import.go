@@ -0,0 +1,184 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reviewer-service/internal/models"
+	"sort"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+type ImportRow struct {
+	Line     int
+	TeamName string
+	UserID   string
+	Username string
+	IsActive string
+	Role     string
+}
+
+type RowError struct {
+	Line    int
+	Message string
+}
+
+// ImportError lists every invalid row; nothing is imported when it is returned.
+type ImportError struct {
+	Rows []RowError
+}
+
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("%d invalid rows", len(e.Rows))
+}
+
+// TeamImport is the result of an import.
+type TeamImport struct {
+	CreatedTeams []string
+	UpdatedTeams []string
+	Users        int
+}
+
+// ExportTeams returns every team that is not archived with its members.
+func (s *Service) ExportTeams(ctx context.Context) ([]models.Team, error) {
+	teams, err := s.repo.GetAllTeams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	active := make([]models.Team, 0, len(teams))
+	for _, team := range teams {
+		if !team.Archived() {
+			active = append(active, team)
+		}
+	}
+	return active, nil
+}
+
+func (s *Service) Import(ctx context.Context, rows []ImportRow, createTeams bool) (*TeamImport, error) {
+	var rowErrors []RowError
+	fail := func(line int, format string, args ...interface{}) {
+		rowErrors = append(rowErrors, RowError{Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	result := &TeamImport{CreatedTeams: []string{}, UpdatedTeams: []string{}}
+	var teams []models.Team
+	teamIndex := map[string]int{}
+	badTeams := map[string]bool{}
+	userLines := map[string]int{}
+	usernameLines := map[string]int{}
+	users := map[string]models.User{}
+	inTeam := map[string]bool{}
+
+	for _, row := range rows {
+		if row.TeamName == "" {
+			fail(row.Line, "team_name is required")
+			continue
+		}
+
+		if _, ok := teamIndex[row.TeamName]; !ok && !badTeams[row.TeamName] {
+			team, err := s.repo.GetTeamByName(ctx, row.TeamName)
+			switch {
+			case errors.Is(err, models.ErrTeamNotFound) && createTeams:
+				result.CreatedTeams = append(result.CreatedTeams, row.TeamName)
+			case errors.Is(err, models.ErrTeamNotFound):
+				fail(row.Line, "unknown team %s", row.TeamName)
+				badTeams[row.TeamName] = true
+			case err != nil:
+				return nil, err
+			case team.Archived():
+				fail(row.Line, "team %s is archived", row.TeamName)
+				badTeams[row.TeamName] = true
+			default:
+				result.UpdatedTeams = append(result.UpdatedTeams, row.TeamName)
+			}
+			if !badTeams[row.TeamName] {
+				teamIndex[row.TeamName] = len(teams)
+				teams = append(teams, models.Team{Name: row.TeamName})
+			}
+		}
+
+		if row.UserID == "" && row.Username == "" {
+			continue
+		}
+
+		userID, err := uuid.Parse(row.UserID)
+		if err != nil {
+			fail(row.Line, "invalid user_id %q", row.UserID)
+			continue
+		}
+		if row.Username == "" {
+			fail(row.Line, "username is required")
+			continue
+		}
+
+		isActive := true
+		if row.IsActive != "" {
+			if isActive, err = strconv.ParseBool(row.IsActive); err != nil {
+				fail(row.Line, "invalid is_active %q", row.IsActive)
+				continue
+			}
+		}
+
+		role, err := ParseRole(row.Role)
+		if err != nil {
+			fail(row.Line, "%v", err)
+			continue
+		}
+
+		user := models.User{ID: userID.String(), Username: row.Username, IsActive: isActive, Role: role}
+		if prev, ok := users[user.ID]; ok {
+			if prev.Username != user.Username || prev.IsActive != user.IsActive {
+				fail(row.Line, "user %s differs from line %d", user.ID, userLines[user.ID])
+				continue
+			}
+		} else if line, ok := usernameLines[user.Username]; ok {
+			fail(row.Line, "duplicate username %s, already used on line %d", user.Username, line)
+			continue
+		} else {
+			users[user.ID] = user
+			userLines[user.ID] = row.Line
+			usernameLines[user.Username] = row.Line
+		}
+
+		if inTeam[row.TeamName+"/"+user.ID] {
+			fail(row.Line, "user %s is listed twice in team %s", user.ID, row.TeamName)
+			continue
+		}
+		inTeam[row.TeamName+"/"+user.ID] = true
+
+		if i, ok := teamIndex[row.TeamName]; ok {
+			teams[i].Members = append(teams[i].Members, user)
+		}
+	}
+
+	if len(usernameLines) > 0 {
+		usernames := make([]string, 0, len(usernameLines))
+		for name := range usernameLines {
+			usernames = append(usernames, name)
+		}
+		existing, err := s.usersRepo.GetByUsernames(ctx, usernames)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range existing {
+			if users[u.ID].Username != u.Username {
+				fail(usernameLines[u.Username], "duplicate username %s, already used by user %s", u.Username, u.ID)
+			}
+		}
+	}
+
+	if len(rowErrors) > 0 {
+		sort.SliceStable(rowErrors, func(i, j int) bool { return rowErrors[i].Line < rowErrors[j].Line })
+		return nil, &ImportError{Rows: rowErrors}
+	}
+
+	if err := s.repo.ImportTeams(ctx, teams); err != nil {
+		return nil, err
+	}
+
+	result.Users = len(users)
+	return result, nil
+}
@@ -2,20 +2,84 @@ package teams
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"reviewer-service/internal/models"
+	"reviewer-service/internal/services/bulk"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNoMembers    = errors.New("members must not be empty")
+	ErrUserNotFound = errors.New("user not found")
+	ErrHasOpenPRs   = errors.New("team members are referenced by open pull requests")
+	ErrSameTeam     = errors.New("target_team must differ from team_name")
+	ErrEmptyName    = errors.New("new_team_name must not be empty")
+	ErrInvalidRole  = errors.New("role must be one of lead, member, observer")
+
+	ErrInvalidSettings = errors.New("invalid team settings")
+	ErrInvalidManifest = errors.New("invalid team manifest")
 )
 
 type TeamsRepository interface {
 	CreateTeam(ctx context.Context, team models.Team) error
 	GetTeamByName(ctx context.Context, name string) (*models.Team, error)
+	GetTeamByID(ctx context.Context, id uuid.UUID) (*models.Team, error)
+	GetAllTeams(ctx context.Context) ([]models.Team, error)
+	ImportTeams(ctx context.Context, teams []models.Team) error
+	RenameTeam(ctx context.Context, id uuid.UUID, name string) error
+	SetParent(ctx context.Context, id, parentID uuid.UUID) error
+	GetChildTeamIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+	SetRole(ctx context.Context, teamName string, userID uuid.UUID, role models.TeamRole) error
+	SetLeadReviewLabels(ctx context.Context, teamName string, labels []string) error
+	GetEscalationLeads(ctx context.Context, id uuid.UUID) ([]*models.User, error)
+	AddUsersToTeam(ctx context.Context, teamName string, users []models.User) error
+	RemoveUserFromTeam(ctx context.Context, teamName string, userID uuid.UUID, deactivate bool, reassignments []models.ReviewerReassignment) error
+	MoveUserToTeam(ctx context.Context, userID uuid.UUID, fromTeam, toTeam string, reassignments []models.ReviewerReassignment) error
+	ArchiveTeam(ctx context.Context, teamName string, op models.BulkOperation, reassignments []models.ReviewerReassignment) error
+	GetExclusiveMembers(ctx context.Context, teamName string) ([]uuid.UUID, error)
+	GetOpenPullRequestIDs(ctx context.Context, teamName string) ([]string, error)
+	DeleteTeam(ctx context.Context, teamName, targetTeam string, reassignments []models.ReviewerReassignment) ([]string, error)
+	SyncTeams(ctx context.Context, actions []models.TeamSyncAction, reassignments []models.ReviewerReassignment) error
+}
+
+type UsersRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	GetByUsernames(ctx context.Context, usernames []string) ([]*models.User, error)
+	GetTeamNames(ctx context.Context, id uuid.UUID) ([]string, error)
+}
+
+type SettingsRepository interface {
+	GetTeamSettings(ctx context.Context, teamID uuid.UUID) (*models.TeamSettingsVersion, error)
+	GetTeamSettingsHistory(ctx context.Context, teamID uuid.UUID) ([]models.TeamSettingsVersion, error)
+	SaveTeamSettings(ctx context.Context, teamID uuid.UUID, expectedVersion int, settings models.TeamSettings) (*models.TeamSettingsVersion, error)
+}
+
+// Reassigner plans where the open reviews of users leaving a team go.
+type Reassigner interface {
+	PlanHandover(ctx context.Context, userIDs []uuid.UUID, opts bulk.Options) (*models.BulkDeactivation, []models.ReviewerReassignment, error)
+	DeactivateUsers(ctx context.Context, userIDs []string, opts bulk.Options) (*models.BulkDeactivation, error)
+	CheckTeamApproval(ctx context.Context, teamName, approvedBy string) error
+	CheckUsersApproval(ctx context.Context, userIDs []string, approvedBy string) error
 }
 
 type Service struct {
-	repo TeamsRepository
+	repo         TeamsRepository
+	usersRepo    UsersRepository
+	settingsRepo SettingsRepository
+	reassigner   Reassigner
 }
 
-func New(repo TeamsRepository) *Service {
-	return &Service{repo: repo}
+func New(repo TeamsRepository, usersRepo UsersRepository, settingsRepo SettingsRepository, reassigner Reassigner) *Service {
+	return &Service{
+		repo:         repo,
+		usersRepo:    usersRepo,
+		settingsRepo: settingsRepo,
+		reassigner:   reassigner,
+	}
 }
 
 func (s *Service) CreateTeam(ctx context.Context, team models.Team) error {
@@ -25,3 +89,444 @@ func (s *Service) CreateTeam(ctx context.Context, team models.Team) error {
 func (s *Service) GetTeam(ctx context.Context, name string) (*models.Team, error) {
 	return s.repo.GetTeamByName(ctx, name)
 }
+
+func (s *Service) GetTeamByID(ctx context.Context, id uuid.UUID) (*models.Team, error) {
+	return s.repo.GetTeamByID(ctx, id)
+}
+
+func (s *Service) RenameTeam(ctx context.Context, id uuid.UUID, name string) (*models.Team, error) {
+	if name == "" {
+		return nil, ErrEmptyName
+	}
+	if err := s.repo.RenameTeam(ctx, id, name); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTeamByID(ctx, id)
+}
+
+// SetParent makes the team top-level when parentName is empty.
+func (s *Service) SetParent(ctx context.Context, teamName, parentName string) (*models.Team, error) {
+	team, err := s.repo.GetTeamByName(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	teamID := uuid.MustParse(team.ID)
+
+	parentID := uuid.Nil
+	if parentName != "" {
+		parent, err := s.repo.GetTeamByName(ctx, parentName)
+		if err != nil {
+			return nil, err
+		}
+		parentID = uuid.MustParse(parent.ID)
+	}
+
+	if err := s.repo.SetParent(ctx, teamID, parentID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTeamByID(ctx, teamID)
+}
+
+// LoadSubTeams fills team.SubTeams with the whole tree of teams below it.
+func (s *Service) LoadSubTeams(ctx context.Context, team *models.Team) error {
+	childIDs, err := s.repo.GetChildTeamIDs(ctx, uuid.MustParse(team.ID))
+	if err != nil {
+		return err
+	}
+
+	team.SubTeams = make([]models.Team, 0, len(childIDs))
+	for _, id := range childIDs {
+		child, err := s.repo.GetTeamByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.LoadSubTeams(ctx, child); err != nil {
+			return err
+		}
+		team.SubTeams = append(team.SubTeams, *child)
+	}
+	return nil
+}
+
+// GetSettings returns the defaults as version 0 for a team that never saved any.
+func (s *Service) GetSettings(ctx context.Context, teamName string) (*models.TeamSettingsVersion, error) {
+	team, err := s.repo.GetTeamByName(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	return s.currentSettings(ctx, team)
+}
+
+func (s *Service) SettingsHistory(ctx context.Context, teamName string) ([]models.TeamSettingsVersion, error) {
+	team, err := s.repo.GetTeamByName(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	return s.settingsRepo.GetTeamSettingsHistory(ctx, uuid.MustParse(team.ID))
+}
+
+// UpdateSettings merges patch into the current settings and saves a new version.
+// An expectedVersion of -1 skips the concurrency check.
+func (s *Service) UpdateSettings(ctx context.Context, teamName string, expectedVersion int, patch []byte) (*models.TeamSettingsVersion, error) {
+	team, err := s.repo.GetTeamByName(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.currentSettings(ctx, team)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := current.Settings
+	settings.FallbackTeamIDs = append([]string(nil), current.Settings.FallbackTeamIDs...)
+	if err := json.Unmarshal(patch, &settings); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSettings, err)
+	}
+	if settings.FallbackTeamIDs == nil {
+		settings.FallbackTeamIDs = []string{}
+	}
+
+	if err := s.validateSettings(ctx, team, settings); err != nil {
+		return nil, err
+	}
+
+	return s.settingsRepo.SaveTeamSettings(ctx, uuid.MustParse(team.ID), expectedVersion, settings)
+}
+
+func (s *Service) currentSettings(ctx context.Context, team *models.Team) (*models.TeamSettingsVersion, error) {
+	v, err := s.settingsRepo.GetTeamSettings(ctx, uuid.MustParse(team.ID))
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		v = &models.TeamSettingsVersion{
+			TeamID:   team.ID,
+			Settings: models.DefaultTeamSettings(),
+		}
+	}
+	return v, nil
+}
+
+func (s *Service) validateSettings(ctx context.Context, team *models.Team, settings models.TeamSettings) error {
+	if settings.ReviewerCount < 1 || settings.ReviewerCount > models.MaxReviewers {
+		return fmt.Errorf("%w: reviewer_count must be between 1 and %d", ErrInvalidSettings, models.MaxReviewers)
+	}
+
+	switch settings.SelectionStrategy {
+	case models.SelectionRandom, models.SelectionLeastLoaded:
+	default:
+		return fmt.Errorf("%w: unknown selection_strategy %q", ErrInvalidSettings, settings.SelectionStrategy)
+	}
+
+	switch settings.ApprovalPolicy {
+	case models.ApprovalNone, models.ApprovalLeadIfPresent, models.ApprovalLeadRequired:
+	default:
+		return fmt.Errorf("%w: unknown approval_policy %q", ErrInvalidSettings, settings.ApprovalPolicy)
+	}
+
+	if settings.SLA.FirstReviewHours < 0 || settings.SLA.MergeHours < 0 {
+		return fmt.Errorf("%w: sla thresholds must not be negative", ErrInvalidSettings)
+	}
+
+	seen := map[string]bool{}
+	for _, id := range settings.FallbackTeamIDs {
+		teamID, err := uuid.Parse(id)
+		if err != nil {
+			return fmt.Errorf("%w: invalid fallback team id %s", ErrInvalidSettings, id)
+		}
+		if teamID.String() == team.ID || seen[teamID.String()] {
+			return fmt.Errorf("%w: fallback team %s is the team itself or listed twice", ErrInvalidSettings, id)
+		}
+		seen[teamID.String()] = true
+		if _, err := s.repo.GetTeamByID(ctx, teamID); err != nil {
+			if errors.Is(err, models.ErrTeamNotFound) {
+				return fmt.Errorf("%w: fallback team %s not found", ErrInvalidSettings, id)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ParseRole validates a role given by the API; an empty value means member.
+func ParseRole(value string) (models.TeamRole, error) {
+	switch r := models.TeamRole(value); r {
+	case "":
+		return models.TeamRoleMember, nil
+	case models.TeamRoleLead, models.TeamRoleMember, models.TeamRoleObserver:
+		return r, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrInvalidRole, value)
+	}
+}
+
+func (s *Service) SetRole(ctx context.Context, teamName string, userID uuid.UUID, role models.TeamRole) error {
+	return s.repo.SetRole(ctx, teamName, userID, role)
+}
+
+func (s *Service) SetLeadReviewLabels(ctx context.Context, teamName string, labels []string) (*models.Team, error) {
+	if err := s.repo.SetLeadReviewLabels(ctx, teamName, labels); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTeamByName(ctx, teamName)
+}
+
+func (s *Service) EscalationLeads(ctx context.Context, team *models.Team) ([]*models.User, error) {
+	return s.repo.GetEscalationLeads(ctx, uuid.MustParse(team.ID))
+}
+
+func (s *Service) AddMembers(ctx context.Context, teamName string, members []models.User) error {
+	if len(members) == 0 {
+		return ErrNoMembers
+	}
+	return s.repo.AddUsersToTeam(ctx, teamName, members)
+}
+
+// RemoveMember takes the user out of the team. Removing the last membership
+// deactivates the user and needs the team's approval.
+func (s *Service) RemoveMember(ctx context.Context, teamName string, userID uuid.UUID, approvedBy string, fallback bulk.Fallback) (*models.MembershipChange, error) {
+	user, teams, err := s.member(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !contains(teams, teamName) {
+		return nil, models.ErrNotTeamMember
+	}
+
+	opts := bulk.Options{Fallback: fallback}
+	deactivate := len(teams) == 1
+	if deactivate {
+		if err := s.reassigner.CheckUsersApproval(ctx, []string{user.ID}, approvedBy); err != nil {
+			return nil, err
+		}
+	} else {
+		opts.AuthorTeam = teamName
+	}
+
+	plan, reassignments, err := s.reassigner.PlanHandover(ctx, []uuid.UUID{userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.RemoveUserFromTeam(ctx, teamName, userID, deactivate, reassignments); err != nil {
+		return nil, err
+	}
+
+	return newMembershipChange(user.ID, teamName, "", plan), nil
+}
+
+// MoveMember moves the user from fromTeam, their primary team when empty.
+func (s *Service) MoveMember(ctx context.Context, userID uuid.UUID, fromTeam, toTeam string, fallback bulk.Fallback) (*models.MembershipChange, error) {
+	user, teams, err := s.member(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetTeamByName(ctx, toTeam); err != nil {
+		return nil, err
+	}
+
+	if fromTeam == "" {
+		fromTeam = user.TeamName
+	}
+	switch {
+	case fromTeam == toTeam:
+		return newMembershipChange(user.ID, fromTeam, toTeam, nil), nil
+	case fromTeam == "":
+		if err := s.repo.AddUsersToTeam(ctx, toTeam, []models.User{*user}); err != nil {
+			return nil, err
+		}
+		return newMembershipChange(user.ID, "", toTeam, nil), nil
+	case !contains(teams, fromTeam):
+		return nil, models.ErrNotTeamMember
+	}
+
+	plan, reassignments, err := s.reassigner.PlanHandover(ctx, []uuid.UUID{userID}, bulk.Options{
+		Fallback:   fallback,
+		AuthorTeam: fromTeam,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.MoveUserToTeam(ctx, userID, fromTeam, toTeam, reassignments); err != nil {
+		return nil, err
+	}
+
+	return newMembershipChange(user.ID, fromTeam, toTeam, plan), nil
+}
+
+// Archive hands over the reviews of exclusive members, deactivates them and
+// archives the team in one transaction.
+func (s *Service) Archive(ctx context.Context, teamName, approvedBy string, fallback bulk.Fallback) (*models.Team, *models.BulkDeactivation, error) {
+	team, err := s.repo.GetTeamByName(ctx, teamName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if team.Archived() {
+		return nil, nil, models.ErrTeamArchived
+	}
+	if err := s.reassigner.CheckTeamApproval(ctx, teamName, approvedBy); err != nil {
+		return nil, nil, err
+	}
+
+	exclusive, err := s.repo.GetExclusiveMembers(ctx, teamName)
+	if err != nil {
+		return nil, nil, err
+	}
+	active := map[string]bool{}
+	for _, m := range team.Members {
+		active[m.ID] = m.IsActive
+	}
+	var deactivating []uuid.UUID
+	for _, id := range exclusive {
+		if active[id.String()] {
+			deactivating = append(deactivating, id)
+		}
+	}
+
+	result := &models.BulkDeactivation{
+		DeactivatedUsers: []string{},
+		ReassignedPRs:    []models.PRReassignment{},
+		UnresolvedPRs:    []models.UnresolvedPR{},
+	}
+	var reassignments []models.ReviewerReassignment
+	if len(deactivating) > 0 {
+		result, reassignments, err = s.reassigner.PlanHandover(ctx, deactivating, bulk.Options{Fallback: fallback})
+		if err != nil {
+			return nil, nil, err
+		}
+		result.OperationID = uuid.New().String()
+		result.PlanToken = ""
+	}
+
+	op := models.BulkOperation{
+		ID:         result.OperationID,
+		Kind:       models.BulkOperationDeactivateTeam,
+		TeamName:   teamName,
+		ApprovedBy: approvedBy,
+		UserIDs:    result.DeactivatedUsers,
+	}
+	if err := s.repo.ArchiveTeam(ctx, teamName, op, reassignments); err != nil {
+		return nil, nil, err
+	}
+
+	team, err = s.repo.GetTeamByName(ctx, teamName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return team, result, nil
+}
+
+// Delete removes the team for good.
+func (s *Service) Delete(ctx context.Context, teamName, targetTeam, approvedBy string, fallback bulk.Fallback) (*models.TeamDeletion, error) {
+	team, err := s.repo.GetTeamByName(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if targetTeam == teamName {
+		return nil, ErrSameTeam
+	}
+	if err := s.reassigner.CheckTeamApproval(ctx, teamName, approvedBy); err != nil {
+		return nil, err
+	}
+
+	result := &models.TeamDeletion{
+		TeamName:      teamName,
+		TargetTeam:    targetTeam,
+		DetachedUsers: []string{},
+		MovedPRs:      []string{},
+		ReassignedPRs: []models.PRReassignment{},
+		UnresolvedPRs: []models.UnresolvedPR{},
+	}
+
+	exclusive, err := s.repo.GetExclusiveMembers(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	prIDs, err := s.repo.GetOpenPullRequestIDs(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	var reassignments []models.ReviewerReassignment
+	moveTo := ""
+	if len(prIDs) > 0 {
+		if targetTeam == "" {
+			return nil, fmt.Errorf("%w: %s", ErrHasOpenPRs, strings.Join(prIDs, ", "))
+		}
+
+		target, err := s.repo.GetTeamByName(ctx, targetTeam)
+		if err != nil {
+			return nil, err
+		}
+		if target.Archived() {
+			return nil, models.ErrTeamArchived
+		}
+
+		plan, planned, err := s.reassigner.PlanHandover(ctx, exclusive, bulk.Options{
+			Fallback:     fallback,
+			ReviewerTeam: targetTeam,
+		})
+		if err != nil {
+			return nil, err
+		}
+		reassignments = planned
+		moveTo = targetTeam
+		result.ReassignedPRs = plan.ReassignedPRs
+		result.UnresolvedPRs = plan.UnresolvedPRs
+	}
+
+	movedPRs, err := s.repo.DeleteTeam(ctx, teamName, moveTo, reassignments)
+	if err != nil {
+		return nil, err
+	}
+	result.MovedPRs = movedPRs
+
+	for _, member := range team.Members {
+		result.DetachedUsers = append(result.DetachedUsers, member.ID)
+	}
+	return result, nil
+}
+
+func (s *Service) member(ctx context.Context, userID uuid.UUID) (*models.User, []string, error) {
+	user, err := s.usersRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil {
+		return nil, nil, ErrUserNotFound
+	}
+
+	teams, err := s.usersRepo.GetTeamNames(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, teams, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func newMembershipChange(userID, fromTeam, toTeam string, plan *models.BulkDeactivation) *models.MembershipChange {
+	change := &models.MembershipChange{
+		UserID:        userID,
+		FromTeam:      fromTeam,
+		ToTeam:        toTeam,
+		ReassignedPRs: []models.PRReassignment{},
+		UnresolvedPRs: []models.UnresolvedPR{},
+	}
+	if plan != nil {
+		change.ReassignedPRs = plan.ReassignedPRs
+		change.UnresolvedPRs = plan.UnresolvedPRs
+	}
+	return change
+}
@@ -0,0 +1,353 @@
+package teams
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reviewer-service/internal/models"
+	"reviewer-service/internal/services/bulk"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// PlanSync is the dry-run counterpart of Sync.
+func (s *Service) PlanSync(ctx context.Context, manifest models.TeamManifest, fallback bulk.Fallback) (*models.TeamSync, error) {
+	plan, _, err := s.planSync(ctx, manifest, fallback)
+	return plan, err
+}
+
+// Sync applies the plan of PlanSync in one transaction. Replacements are picked
+// anew; planToken only guards the actions and the affected reviews.
+func (s *Service) Sync(ctx context.Context, manifest models.TeamManifest, fallback bulk.Fallback, planToken string) (*models.TeamSync, error) {
+	plan, reassignments, err := s.planSync(ctx, manifest, fallback)
+	if err != nil {
+		return nil, err
+	}
+	if planToken != "" && planToken != plan.PlanToken {
+		return nil, bulk.ErrPlanStale
+	}
+
+	if err := s.repo.SyncTeams(ctx, plan.Actions, reassignments); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (s *Service) planSync(ctx context.Context, manifest models.TeamManifest, fallback bulk.Fallback) (*models.TeamSync, []models.ReviewerReassignment, error) {
+	desired, usernames, err := validateManifest(manifest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var teamNames []string
+	parents := map[string]string{}
+	current := map[string]*models.Team{}
+	for _, t := range manifest.Teams {
+		teamNames = append(teamNames, t.Name)
+		parents[t.Name] = t.Parent
+
+		team, err := s.repo.GetTeamByName(ctx, t.Name)
+		switch {
+		case errors.Is(err, models.ErrTeamNotFound):
+			continue
+		case err != nil:
+			return nil, nil, err
+		case team.Archived():
+			return nil, nil, fmt.Errorf("%w: %s", models.ErrTeamArchived, t.Name)
+		}
+		current[t.Name] = team
+	}
+
+	actions, err := s.planTeams(ctx, teamNames, parents, current)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	known := map[string]*models.User{}
+	currentRoles := map[string]map[string]models.TeamRole{}
+	var userIDs []string
+	seen := map[string]bool{}
+	for _, t := range manifest.Teams {
+		for _, m := range t.Members {
+			if !seen[m.UserID] {
+				seen[m.UserID] = true
+				userIDs = append(userIDs, m.UserID)
+			}
+		}
+	}
+	for _, name := range teamNames {
+		currentRoles[name] = map[string]models.TeamRole{}
+		team := current[name]
+		if team == nil {
+			continue
+		}
+		for i := range team.Members {
+			member := &team.Members[i]
+			currentRoles[name][member.ID] = member.Role
+			known[member.ID] = member
+			if !seen[member.ID] {
+				seen[member.ID] = true
+				userIDs = append(userIDs, member.ID)
+			}
+		}
+	}
+
+	leaving := map[string][]string{}
+	var handover []uuid.UUID
+	for _, userID := range userIDs {
+		var removed, added []string
+		for _, name := range teamNames {
+			role, isMember := currentRoles[name][userID]
+			wanted, wants := desired[name][userID]
+			switch {
+			case isMember && !wants:
+				removed = append(removed, name)
+			case !isMember && wants:
+				added = append(added, name)
+			case isMember && role != wanted:
+				actions = append(actions, models.TeamSyncAction{Kind: models.TeamSyncSetRole, TeamName: name, UserID: userID, Role: wanted})
+			}
+		}
+
+		username, listed := usernames[userID]
+		if listed {
+			user := known[userID]
+			if user == nil {
+				user, err = s.usersRepo.GetByID(ctx, uuid.MustParse(userID))
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+			switch {
+			case user == nil:
+				actions = append(actions, models.TeamSyncAction{Kind: models.TeamSyncCreateUser, UserID: userID, Username: username})
+			case user.Username != username || !user.IsActive:
+				actions = append(actions, models.TeamSyncAction{Kind: models.TeamSyncUpdateUser, UserID: userID, Username: username})
+			}
+		}
+
+		for len(removed) > 0 && len(added) > 0 {
+			actions = append(actions, models.TeamSyncAction{
+				Kind:     models.TeamSyncMoveMember,
+				TeamName: added[0],
+				UserID:   userID,
+				FromTeam: removed[0],
+				Role:     desired[added[0]][userID],
+			})
+			leaving[userID] = append(leaving[userID], removed[0])
+			removed, added = removed[1:], added[1:]
+		}
+		for _, name := range added {
+			actions = append(actions, models.TeamSyncAction{Kind: models.TeamSyncAddMember, TeamName: name, UserID: userID, Role: desired[name][userID]})
+		}
+		for _, name := range removed {
+			actions = append(actions, models.TeamSyncAction{Kind: models.TeamSyncRemoveMember, TeamName: name, UserID: userID})
+			leaving[userID] = append(leaving[userID], name)
+		}
+
+		if len(leaving[userID]) == 0 {
+			continue
+		}
+		handover = append(handover, uuid.MustParse(userID))
+		if listed {
+			continue
+		}
+
+		teams, err := s.usersRepo.GetTeamNames(ctx, uuid.MustParse(userID))
+		if err != nil {
+			return nil, nil, err
+		}
+		remaining := 0
+		for _, name := range teams {
+			if _, ok := desired[name]; !ok {
+				remaining++
+			}
+		}
+		if remaining == 0 && known[userID].IsActive {
+			actions = append(actions, models.TeamSyncAction{Kind: models.TeamSyncDeactivateUser, UserID: userID})
+			delete(leaving, userID)
+		}
+	}
+
+	result := &models.TeamSync{
+		Actions:       actions,
+		ReassignedPRs: []models.PRReassignment{},
+		UnresolvedPRs: []models.UnresolvedPR{},
+	}
+
+	var reassignments []models.ReviewerReassignment
+	if len(handover) > 0 {
+		plan, planned, err := s.reassigner.PlanHandover(ctx, handover, bulk.Options{
+			Fallback:     fallback,
+			LeavingTeams: leaving,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		reassignments = planned
+		result.ReassignedPRs = plan.ReassignedPRs
+		result.UnresolvedPRs = plan.UnresolvedPRs
+	}
+
+	result.PlanToken = syncToken(result)
+	return result, reassignments, nil
+}
+
+func (s *Service) planTeams(ctx context.Context, teamNames []string, parents map[string]string, current map[string]*models.Team) ([]models.TeamSyncAction, error) {
+	parentOf := func(name string) (string, error) {
+		if parent, ok := parents[name]; ok {
+			return parent, nil
+		}
+		team, err := s.repo.GetTeamByName(ctx, name)
+		if err != nil {
+			if errors.Is(err, models.ErrTeamNotFound) {
+				return "", fmt.Errorf("%w: parent team %s not found", ErrInvalidManifest, name)
+			}
+			return "", err
+		}
+		parents[name], err = s.parentName(ctx, team)
+		return parents[name], err
+	}
+
+	for _, name := range teamNames {
+		visited := map[string]bool{name: true}
+		for parent := parents[name]; parent != ""; {
+			if visited[parent] {
+				return nil, fmt.Errorf("%w: team %s is nested under itself", ErrInvalidManifest, name)
+			}
+			visited[parent] = true
+
+			var err error
+			if parent, err = parentOf(parent); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var actions []models.TeamSyncAction
+	created := map[string]bool{}
+	var create func(name string)
+	create = func(name string) {
+		if name == "" || created[name] || current[name] != nil || !contains(teamNames, name) {
+			return
+		}
+		created[name] = true
+		create(parents[name])
+		actions = append(actions, models.TeamSyncAction{Kind: models.TeamSyncCreateTeam, TeamName: name, ParentTeam: parents[name]})
+	}
+
+	for _, name := range teamNames {
+		team := current[name]
+		if team == nil {
+			create(name)
+			continue
+		}
+		parent, err := s.parentName(ctx, team)
+		if err != nil {
+			return nil, err
+		}
+		if parent != parents[name] {
+			actions = append(actions, models.TeamSyncAction{Kind: models.TeamSyncUpdateTeam, TeamName: name, ParentTeam: parents[name]})
+		}
+	}
+
+	return actions, nil
+}
+
+func (s *Service) parentName(ctx context.Context, team *models.Team) (string, error) {
+	if team.ParentID == "" {
+		return "", nil
+	}
+	parent, err := s.repo.GetTeamByID(ctx, uuid.MustParse(team.ParentID))
+	if err != nil {
+		return "", err
+	}
+	return parent.Name, nil
+}
+
+// validateManifest normalizes the user ids of the manifest in place.
+func validateManifest(manifest models.TeamManifest) (map[string]map[string]models.TeamRole, map[string]string, error) {
+	if len(manifest.Teams) == 0 {
+		return nil, nil, fmt.Errorf("%w: teams must not be empty", ErrInvalidManifest)
+	}
+
+	desired := map[string]map[string]models.TeamRole{}
+	usernames := map[string]string{}
+	for _, t := range manifest.Teams {
+		if t.Name == "" {
+			return nil, nil, fmt.Errorf("%w: team name must not be empty", ErrInvalidManifest)
+		}
+		if _, ok := desired[t.Name]; ok {
+			return nil, nil, fmt.Errorf("%w: team %s is listed twice", ErrInvalidManifest, t.Name)
+		}
+		if t.Parent == t.Name {
+			return nil, nil, fmt.Errorf("%w: team %s is nested under itself", ErrInvalidManifest, t.Name)
+		}
+
+		roles := map[string]models.TeamRole{}
+		for i := range t.Members {
+			m := &t.Members[i]
+			userID, err := uuid.Parse(m.UserID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: invalid user_id %s", ErrInvalidManifest, m.UserID)
+			}
+			m.UserID = userID.String()
+
+			if m.Username == "" {
+				return nil, nil, fmt.Errorf("%w: username of %s must not be empty", ErrInvalidManifest, m.UserID)
+			}
+			if name, ok := usernames[m.UserID]; ok && name != m.Username {
+				return nil, nil, fmt.Errorf("%w: user %s has different usernames", ErrInvalidManifest, m.UserID)
+			}
+			if _, ok := roles[m.UserID]; ok {
+				return nil, nil, fmt.Errorf("%w: user %s is listed twice in team %s", ErrInvalidManifest, m.UserID, t.Name)
+			}
+
+			role, err := ParseRole(string(m.Role))
+			if err != nil {
+				return nil, nil, err
+			}
+			roles[m.UserID] = role
+			usernames[m.UserID] = m.Username
+		}
+		desired[t.Name] = roles
+	}
+
+	return desired, usernames, nil
+}
+
+// syncToken leaves out the randomly picked replacements.
+func syncToken(plan *models.TeamSync) string {
+	var b strings.Builder
+	for _, a := range plan.Actions {
+		b.WriteString("action:" + string(a.Kind) + "|" + a.TeamName + "|" + a.ParentTeam + "|" + a.UserID + "|" +
+			a.Username + "|" + a.FromTeam + "|" + string(a.Role) + "\n")
+	}
+
+	affected := map[string][]string{}
+	for _, item := range plan.ReassignedPRs {
+		affected[item.PRID] = append(affected[item.PRID], item.Replaced...)
+		affected[item.PRID] = append(affected[item.PRID], item.Removed...)
+	}
+	for _, item := range plan.UnresolvedPRs {
+		affected[item.PRID] = append(affected[item.PRID], item.Reviewers...)
+	}
+
+	prIDs := make([]string, 0, len(affected))
+	for id := range affected {
+		prIDs = append(prIDs, id)
+	}
+	sort.Strings(prIDs)
+	for _, id := range prIDs {
+		reviewers := affected[id]
+		sort.Strings(reviewers)
+		b.WriteString("pr:" + id + "|" + strings.Join(reviewers, ",") + "\n")
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
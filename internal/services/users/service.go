@@ -2,12 +2,22 @@ package users
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"reviewer-service/internal/models"
 	"reviewer-service/internal/repository/users"
+	"sort"
 
 	"github.com/google/uuid"
 )
 
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+var ErrInvalidFilter = errors.New("invalid user filter")
+
 type Service struct {
 	repo users.Repository
 }
@@ -24,6 +34,47 @@ func (s *Service) GetUser(ctx context.Context, id uuid.UUID) (*models.User, erro
 	return s.repo.GetByID(ctx, id)
 }
 
+func (s *Service) GetProfile(ctx context.Context, id uuid.UUID) (*models.UserProfile, error) {
+	return s.repo.GetProfile(ctx, id)
+}
+
+// ListUsers returns a page of matching users and their total count.
+func (s *Service) ListUsers(ctx context.Context, filter models.UserFilter) ([]*models.UserProfile, int, error) {
+	switch filter.Role {
+	case "", models.TeamRoleLead, models.TeamRoleMember, models.TeamRoleObserver:
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown role %s", ErrInvalidFilter, filter.Role)
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultPageSize
+	}
+	if filter.Limit < 0 || filter.Limit > MaxPageSize {
+		return nil, 0, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidFilter, MaxPageSize)
+	}
+	if filter.Offset < 0 {
+		return nil, 0, fmt.Errorf("%w: offset must not be negative", ErrInvalidFilter)
+	}
+
+	profiles, err := s.repo.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.repo.CountUsers(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return profiles, total, nil
+}
+
+// GetTeamNames returns the teams the user belongs to, primary team first.
+func (s *Service) GetTeamNames(ctx context.Context, id uuid.UUID) ([]string, error) {
+	return s.repo.GetTeamNames(ctx, id)
+}
+
+func (s *Service) SetPrimaryTeam(ctx context.Context, id uuid.UUID, teamName string) error {
+	return s.repo.SetPrimaryTeam(ctx, id, teamName)
+}
+
 func (s *Service) GetTeamUsers(ctx context.Context, team string) ([]models.User, error) {
 	users, err := s.repo.GetActiveByTeam(ctx, team)
 	if err != nil {
@@ -44,6 +95,71 @@ func (s *Service) GetAssignedPullRequests(ctx context.Context, userID uuid.UUID)
 	return s.repo.GetAssignedPullRequests(ctx, userID)
 }
 
+// GetAuthoredPullRequests returns nil when there is no such user.
+func (s *Service) GetAuthoredPullRequests(ctx context.Context, userID uuid.UUID, status models.PullRequestStatus) (*models.AuthoredPullRequests, error) {
+	switch status {
+	case "", models.PullRequestStatusOpen, models.PullRequestStatusMerged:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %s", ErrInvalidFilter, status)
+	}
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	prs, err := s.repo.GetAuthoredPullRequests(ctx, userID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.AuthoredPullRequests{PullRequests: prs, BlockedOn: []models.BlockingReviewer{}}
+	blockedOn := map[string]int{}
+	for _, pr := range prs {
+		switch pr.Status {
+		case models.PullRequestStatusOpen:
+			result.Open++
+		case models.PullRequestStatusMerged:
+			result.Merged++
+		}
+		if !pr.Blocked() {
+			continue
+		}
+
+		result.Blocked++
+		if len(pr.Reviews) == 0 {
+			result.Unassigned++
+		}
+		for _, review := range pr.Reviews {
+			if !review.Blocking() {
+				continue
+			}
+			i, ok := blockedOn[review.ReviewerID]
+			if !ok {
+				i = len(result.BlockedOn)
+				blockedOn[review.ReviewerID] = i
+				result.BlockedOn = append(result.BlockedOn, models.BlockingReviewer{ReviewerID: review.ReviewerID})
+			}
+			reviewer := &result.BlockedOn[i]
+			if review.Verdict == models.ReviewVerdictChangesRequested {
+				reviewer.ChangesRequested++
+			} else {
+				reviewer.Pending++
+			}
+			reviewer.PRIDs = append(reviewer.PRIDs, pr.ID)
+		}
+	}
+
+	sort.SliceStable(result.BlockedOn, func(i, j int) bool {
+		a, b := result.BlockedOn[i], result.BlockedOn[j]
+		if len(a.PRIDs) != len(b.PRIDs) {
+			return len(a.PRIDs) > len(b.PRIDs)
+		}
+		return a.ReviewerID < b.ReviewerID
+	})
+	return result, nil
+}
+
 func (s *Service) SetTeamInactive(ctx context.Context, teamName string) error {
 	return s.repo.SetTeamInactive(ctx, teamName)
 }
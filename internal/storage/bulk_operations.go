@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reviewer-service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type BulkOperationsRepo struct {
+	db *sql.DB
+}
+
+func NewBulkOperationsRepo(db *sql.DB) *BulkOperationsRepo {
+	return &BulkOperationsRepo{db: db}
+}
+
+func (r *BulkOperationsRepo) GetBulkOperation(ctx context.Context, id uuid.UUID) (*models.BulkOperation, error) {
+	op := &models.BulkOperation{}
+	var opID uuid.UUID
+	var teamName sql.NullString
+	var approvedBy uuid.NullUUID
+	var reactivatedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT operation_id, kind, team_name, approved_by, created_at, reactivated_at
+		FROM bulk_operations
+		WHERE operation_id = $1
+	`, id).Scan(&opID, &op.Kind, &teamName, &approvedBy, &op.CreatedAt, &reactivatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrOperationNotFound
+		}
+		return nil, fmt.Errorf("query operation: %w", err)
+	}
+	op.ID = opID.String()
+	op.TeamName = teamName.String
+	if approvedBy.Valid {
+		op.ApprovedBy = approvedBy.UUID.String()
+	}
+	if reactivatedAt.Valid {
+		op.ReactivatedAt = &reactivatedAt.Time
+	}
+
+	userRows, err := r.db.QueryContext(ctx, `
+		SELECT user_id FROM bulk_operation_users WHERE operation_id = $1
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("query operation users: %w", err)
+	}
+	defer userRows.Close()
+
+	for userRows.Next() {
+		var userID uuid.UUID
+		if err := userRows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("scan operation user: %w", err)
+		}
+		op.UserIDs = append(op.UserIDs, userID.String())
+	}
+	if err := userRows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT pull_request_id, old_reviewer_id, new_reviewer_id
+		FROM bulk_operation_reassignments
+		WHERE operation_id = $1
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("query operation reassignments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.ReviewerReassignment
+		var newID uuid.NullUUID
+		if err := rows.Scan(&item.PRID, &item.OldReviewerID, &newID); err != nil {
+			return nil, fmt.Errorf("scan operation reassignment: %w", err)
+		}
+		if newID.Valid {
+			item.NewReviewerID = newID.UUID
+		}
+		op.Reassignments = append(op.Reassignments, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return op, nil
+}
+
+func insertBulkOperation(ctx context.Context, tx *sql.Tx, op models.BulkOperation) error {
+	var teamName interface{}
+	if op.TeamName != "" {
+		teamName = op.TeamName
+	}
+
+	var approvedBy interface{}
+	if op.ApprovedBy != "" {
+		approvedBy = op.ApprovedBy
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO bulk_operations (operation_id, kind, team_name, approved_by)
+		VALUES ($1, $2, $3, $4)
+	`, op.ID, op.Kind, teamName, approvedBy)
+	if err != nil {
+		return fmt.Errorf("insert operation: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO bulk_operation_users (operation_id, user_id)
+		SELECT $1, unnest($2::uuid[])
+	`, op.ID, stringArray(op.UserIDs))
+	if err != nil {
+		return fmt.Errorf("insert operation users: %w", err)
+	}
+
+	for _, item := range op.Reassignments {
+		var newID interface{}
+		if item.NewReviewerID != uuid.Nil {
+			newID = item.NewReviewerID
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO bulk_operation_reassignments (operation_id, pull_request_id, old_reviewer_id, new_reviewer_id)
+			VALUES ($1, $2, $3, $4)
+		`, op.ID, item.PRID, item.OldReviewerID, newID)
+		if err != nil {
+			return fmt.Errorf("insert operation reassignment: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// applyReassignments interprets uuid.Nil ids as documented on models.ReviewerReassignment.
+func applyReassignments(ctx context.Context, tx *sql.Tx, reassignments []models.ReviewerReassignment) error {
+	for _, item := range reassignments {
+		var err error
+		switch {
+		case item.NewReviewerID == uuid.Nil:
+			_, err = tx.ExecContext(ctx, `
+				DELETE FROM pr_reviewers
+				WHERE pull_request_id = $1 AND reviewer_id = $2
+			`, item.PRID, item.OldReviewerID)
+		case item.OldReviewerID == uuid.Nil:
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO pr_reviewers (pull_request_id, reviewer_id, order_index)
+				SELECT $1, $2, MIN(slot)
+				FROM generate_series(1, $3::int) AS slots(slot)
+				WHERE slot NOT IN (SELECT order_index FROM pr_reviewers WHERE pull_request_id = $1)
+			`, item.PRID, item.NewReviewerID, models.MaxReviewers)
+		default:
+			_, err = tx.ExecContext(ctx, `
+				UPDATE pr_reviewers
+				SET reviewer_id = $1, verdict = 'PENDING', decided_at = NULL, assigned_at = now()
+				WHERE pull_request_id = $2 AND reviewer_id = $3
+			`, item.NewReviewerID, item.PRID, item.OldReviewerID)
+		}
+		if err != nil {
+			return fmt.Errorf("reassign reviewer on %s: %w", item.PRID, err)
+		}
+	}
+	return nil
+}
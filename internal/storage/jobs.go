@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reviewer-service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type JobsRepo struct {
+	db *sql.DB
+}
+
+func NewJobsRepo(db *sql.DB) *JobsRepo {
+	return &JobsRepo{db: db}
+}
+
+const jobColumns = `job_id, kind, status, params, progress, total, result, error, created_at, updated_at, finished_at`
+
+func (r *JobsRepo) CreateJob(ctx context.Context, job models.Job) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO jobs (job_id, kind, status, params, created_at, updated_at)
+		VALUES ($1, $2, $3, $4::jsonb, $5, $5)
+	`, job.ID, job.Kind, job.Status, string(job.Params), job.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert job: %w", err)
+	}
+	return nil
+}
+
+func (r *JobsRepo) GetJob(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+jobColumns+` FROM jobs WHERE job_id = $1`, id)
+
+	job, err := scanJob(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("query job: %w", err)
+	}
+	return job, nil
+}
+
+// GetUnfinishedJobs returns jobs that were pending or running, oldest first.
+func (r *JobsRepo) GetUnfinishedJobs(ctx context.Context) ([]models.Job, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+jobColumns+`
+		FROM jobs
+		WHERE status IN ('PENDING', 'RUNNING')
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []models.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan job: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func (r *JobsRepo) StartJob(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE jobs
+		SET status = 'RUNNING', progress = 0, total = 0, updated_at = now()
+		WHERE job_id = $1
+	`, id)
+	return err
+}
+
+func (r *JobsRepo) UpdateProgress(ctx context.Context, id uuid.UUID, progress, total int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE jobs
+		SET progress = $2, total = $3, updated_at = now()
+		WHERE job_id = $1 AND status = 'RUNNING'
+	`, id, progress, total)
+	return err
+}
+
+// FinishJob records the final state of a job; finished jobs are left untouched.
+func (r *JobsRepo) FinishJob(ctx context.Context, id uuid.UUID, status models.JobStatus, result []byte, errMsg string) error {
+	var resultParam, errParam interface{}
+	if result != nil {
+		resultParam = string(result)
+	}
+	if errMsg != "" {
+		errParam = errMsg
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE jobs
+		SET status = $2, result = $3::jsonb, error = $4, updated_at = now(), finished_at = now()
+		WHERE job_id = $1 AND status IN ('PENDING', 'RUNNING')
+	`, id, status, resultParam, errParam)
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanJob(row rowScanner) (*models.Job, error) {
+	var job models.Job
+	var jobID uuid.UUID
+	var errMsg sql.NullString
+	var finishedAt sql.NullTime
+
+	err := row.Scan(
+		&jobID,
+		&job.Kind,
+		&job.Status,
+		&job.Params,
+		&job.Progress,
+		&job.Total,
+		&job.Result,
+		&errMsg,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&finishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	job.ID = jobID.String()
+	job.Error = errMsg.String
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	return &job, nil
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type PullRequestsRepo struct {
@@ -19,31 +20,56 @@ func NewPullRequestsRepo(db *sql.DB) *PullRequestsRepo {
 }
 
 func (r *PullRequestsRepo) CreatePullRequest(ctx context.Context, pr models.PullRequest) error {
-	_, err := r.db.ExecContext(ctx, `
-        INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at)
-        VALUES ($1, $2, $3, $4, $5)
-    `, pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.CreatedAt)
+	labels := pr.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at, labels)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.CreatedAt, stringArray(labels))
 	if err != nil {
 		if strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "unique constraint") {
 			return fmt.Errorf("PR id already exists")
 		}
+		return err
 	}
-	return err
+
+	for i, reviewerID := range pr.Reviewers {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO pr_reviewers (pull_request_id, reviewer_id, order_index)
+			VALUES ($1, $2, $3)
+		`, pr.ID, reviewerID, i+1)
+		if err != nil {
+			return fmt.Errorf("assign reviewer: %w", err)
+		}
+	}
+
+	return tx.Commit()
 }
 
 func (r *PullRequestsRepo) GetPullRequestByID(ctx context.Context, id string) (*models.PullRequest, error) {
 	var pr models.PullRequest
+	var labels pq.StringArray
 	err := r.db.QueryRowContext(ctx, `
-        SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at
-        FROM pull_requests
-        WHERE pull_request_id = $1
-    `, id).Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt)
+		SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at, labels
+		FROM pull_requests
+		WHERE pull_request_id = $1
+	`, id).Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &labels)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("pull request not found")
 		}
 		return nil, err
 	}
+	pr.Labels = []string(labels)
 
 	rows, err := r.db.QueryContext(ctx, `
         SELECT reviewer_id
@@ -94,13 +120,64 @@ func (r *PullRequestsRepo) ReassignReviewer(ctx context.Context, prID, oldReview
 		return fmt.Errorf("invalid new_reviewer_id: %w", err)
 	}
 	_, err = r.db.ExecContext(ctx, `
-        UPDATE pr_reviewers
-        SET reviewer_id = $1
-        WHERE pull_request_id = $2 AND reviewer_id = $3
-    `, newUUID, prID, oldUUID)
+		UPDATE pr_reviewers
+		SET reviewer_id = $1, verdict = 'PENDING', decided_at = NULL, assigned_at = now()
+		WHERE pull_request_id = $2 AND reviewer_id = $3
+	`, newUUID, prID, oldUUID)
 	return err
 }
 
+func (r *PullRequestsRepo) SetReviewVerdict(ctx context.Context, prID string, reviewerID uuid.UUID, verdict models.ReviewVerdict) (*models.Review, error) {
+	var review models.Review
+	var id uuid.UUID
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE pr_reviewers
+		SET verdict = $3,
+			decided_at = CASE WHEN $3 = 'PENDING' THEN NULL ELSE now() END
+		WHERE pull_request_id = $1 AND reviewer_id = $2
+		RETURNING reviewer_id, verdict, assigned_at, decided_at
+	`, prID, reviewerID, string(verdict)).Scan(&id, &review.Verdict, &review.AssignedAt, &review.DecidedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	review.ReviewerID = id.String()
+	return &review, nil
+}
+
+// GetOpenReviewCounts returns how many OPEN PRs each of the users reviews.
+func (r *PullRequestsRepo) GetOpenReviewCounts(ctx context.Context, userIDs []uuid.UUID) (map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT rev.reviewer_id, COUNT(*)
+		FROM pr_reviewers rev
+		JOIN pull_requests pr ON pr.pull_request_id = rev.pull_request_id
+		WHERE pr.status = 'OPEN' AND rev.reviewer_id = ANY($1::uuid[])
+		GROUP BY rev.reviewer_id
+	`, uuidArray(userIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var userID uuid.UUID
+		var count int
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, err
+		}
+		counts[userID.String()] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
 func (r *PullRequestsRepo) GetPRsByReviewer(ctx context.Context, userID string) ([]models.PullRequest, error) {
 	userUUID, err := uuid.Parse(userID)
 	if err != nil {
@@ -142,20 +219,28 @@ func (r *PullRequestsRepo) GetPRsByReviewer(ctx context.Context, userID string)
 }
 
 func (r *PullRequestsRepo) GetOpenPRsWithInactiveReviewers(ctx context.Context, inactiveUserIDs []uuid.UUID) ([]models.PullRequest, error) {
+	return openPRsWithReviewers(ctx, r.db, inactiveUserIDs)
+}
+
+func openPRsWithReviewers(ctx context.Context, q queryer, inactiveUserIDs []uuid.UUID) ([]models.PullRequest, error) {
 	if len(inactiveUserIDs) == 0 {
 		return []models.PullRequest{}, nil
 	}
 
 	query := `
-		SELECT DISTINCT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at,
+		       array_agg(rev.reviewer_id::text ORDER BY rev.order_index) AS reviewers
 		FROM pull_requests pr
 		JOIN pr_reviewers rev ON pr.pull_request_id = rev.pull_request_id
 		WHERE pr.status = 'OPEN'
-		  AND rev.reviewer_id = ANY($1::uuid[])
+		  AND pr.pull_request_id IN (
+		      SELECT pull_request_id FROM pr_reviewers WHERE reviewer_id = ANY($1::uuid[])
+		  )
+		GROUP BY pr.pull_request_id
 		ORDER BY pr.created_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, inactiveUserIDs)
+	rows, err := q.QueryContext(ctx, query, uuidArray(inactiveUserIDs))
 	if err != nil {
 		return nil, fmt.Errorf("query open PRs: %w", err)
 	}
@@ -165,27 +250,54 @@ func (r *PullRequestsRepo) GetOpenPRsWithInactiveReviewers(ctx context.Context,
 	for rows.Next() {
 		var pr models.PullRequest
 		var mergedAt sql.NullTime
-		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &mergedAt); err != nil {
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &mergedAt, pq.Array(&pr.Reviewers)); err != nil {
 			return nil, fmt.Errorf("scan PR: %w", err)
 		}
 		if mergedAt.Valid {
 			pr.MergedAt = &mergedAt.Time
 		}
 
-		revRows, _ := r.db.QueryContext(ctx, `
-			SELECT reviewer_id FROM pr_reviewers WHERE pull_request_id = $1 ORDER BY order_index
-		`, pr.ID)
-		for revRows.Next() {
-			var revID uuid.UUID
-			if err := revRows.Scan(&revID); err == nil {
-				pr.Reviewers = append(pr.Reviewers, revID.String())
-			}
-		}
-		revRows.Close()
+		prs = append(prs, pr)
+	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return prs, nil
+}
+
+func (r *PullRequestsRepo) GetPullRequestsByIDs(ctx context.Context, ids []string) ([]models.PullRequest, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at,
+		       COALESCE(array_agg(rev.reviewer_id::text ORDER BY rev.order_index) FILTER (WHERE rev.reviewer_id IS NOT NULL), '{}') AS reviewers
+		FROM pull_requests pr
+		LEFT JOIN pr_reviewers rev ON pr.pull_request_id = rev.pull_request_id
+		WHERE pr.pull_request_id = ANY($1::text[])
+		GROUP BY pr.pull_request_id
+	`, stringArray(ids))
+	if err != nil {
+		return nil, fmt.Errorf("query PRs: %w", err)
+	}
+	defer rows.Close()
+
+	var prs []models.PullRequest
+	for rows.Next() {
+		var pr models.PullRequest
+		var mergedAt sql.NullTime
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &mergedAt, pq.Array(&pr.Reviewers)); err != nil {
+			return nil, fmt.Errorf("scan PR: %w", err)
+		}
+		if mergedAt.Valid {
+			pr.MergedAt = &mergedAt.Time
+		}
 		prs = append(prs, pr)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
 	return prs, nil
 }
 
@@ -202,7 +314,7 @@ func (r *PullRequestsRepo) BulkReassignReviewers(ctx context.Context, reassignme
 
 	stmt, err := tx.PrepareContext(ctx, `
 		UPDATE pr_reviewers
-		SET reviewer_id = $1
+		SET reviewer_id = $1, verdict = 'PENDING', decided_at = NULL, assigned_at = now()
 		WHERE pull_request_id = $2 AND reviewer_id = $3
 	`)
 	if err != nil {
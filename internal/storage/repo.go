@@ -1,6 +1,11 @@
 package storage
 
-import "database/sql"
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
 
 type Repository struct {
 	db *sql.DB
@@ -11,3 +16,16 @@ func New(db *sql.DB) *Repository {
 		db: db,
 	}
 }
+
+// uuidArray converts ids into a value lib/pq can bind to a uuid[] parameter.
+func uuidArray(ids []uuid.UUID) interface{} {
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = id.String()
+	}
+	return pq.Array(strs)
+}
+
+func stringArray(values []string) interface{} {
+	return pq.Array(values)
+}
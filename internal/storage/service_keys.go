@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type ServiceKeysRepo struct {
+	db *sql.DB
+}
+
+func NewServiceKeysRepo(db *sql.DB) *ServiceKeysRepo {
+	return &ServiceKeysRepo{db: db}
+}
+
+// GetOrCreateKey returns the key stored under name, storing key first when
+// there is none yet. Concurrent callers all get the key stored first.
+func (r *ServiceKeysRepo) GetOrCreateKey(ctx context.Context, name string, key []byte) ([]byte, error) {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO service_keys (name, value)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO NOTHING
+	`, name, key)
+	if err != nil {
+		return nil, fmt.Errorf("insert key: %w", err)
+	}
+
+	var stored []byte
+	if err := r.db.QueryRowContext(ctx, "SELECT value FROM service_keys WHERE name = $1", name).Scan(&stored); err != nil {
+		return nil, fmt.Errorf("get key: %w", err)
+	}
+	return stored, nil
+}
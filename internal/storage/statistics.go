@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type StatisticsRepo struct {
@@ -15,19 +18,41 @@ func NewStatisticsRepo(db *sql.DB) *StatisticsRepo {
 }
 
 type UserAssignmentStats struct {
-	UserID   string
-	Username string
-	Count    int
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Count    int    `json:"assignments"`
 }
 
 type PRStats struct {
-	TotalPRs      int
-	OpenPRs       int
-	MergedPRs     int
-	TotalAssignments int
+	TotalPRs         int `json:"total_prs"`
+	OpenPRs          int `json:"open_prs"`
+	MergedPRs        int `json:"merged_prs"`
+	TotalAssignments int `json:"total_assignments"`
+}
+
+// Window is the half-open time range [From, To) statistics cover.
+type Window struct {
+	From time.Time
+	To   time.Time
+}
+
+// windowArgs are the arguments $1 and $2 of every statistics query.
+func windowArgs(window Window) []interface{} {
+	return []interface{}{window.From, window.To}
 }
 
-func (r *StatisticsRepo) GetUserAssignmentStats(ctx context.Context) ([]UserAssignmentStats, error) {
+// teamFilter binds teamIDs to $3; nil teamIDs means no restriction.
+func teamFilter(userColumn string, teamIDs []uuid.UUID) (string, []interface{}) {
+	if teamIDs == nil {
+		return "TRUE", nil
+	}
+	return userColumn + " IN (SELECT user_id FROM team_members WHERE team_id = ANY($3::uuid[]))", []interface{}{uuidArray(teamIDs)}
+}
+
+// GetUserAssignmentStats counts assignments made within the window per user.
+func (r *StatisticsRepo) GetUserAssignmentStats(ctx context.Context, teamIDs []uuid.UUID, window Window) ([]UserAssignmentStats, error) {
+	filter, teamArgs := teamFilter("u.user_id", teamIDs)
+	args := append(windowArgs(window), teamArgs...)
 	query := `
 		SELECT 
 			u.user_id::text,
@@ -35,11 +60,13 @@ func (r *StatisticsRepo) GetUserAssignmentStats(ctx context.Context) ([]UserAssi
 			COUNT(pr.reviewer_id) as assignment_count
 		FROM users u
 		LEFT JOIN pr_reviewers pr ON u.user_id = pr.reviewer_id
+			AND pr.assigned_at >= $1 AND pr.assigned_at < $2
+		WHERE ` + filter + `
 		GROUP BY u.user_id, u.username
 		ORDER BY assignment_count DESC, u.username
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query user stats: %w", err)
 	}
@@ -61,18 +88,23 @@ func (r *StatisticsRepo) GetUserAssignmentStats(ctx context.Context) ([]UserAssi
 	return stats, nil
 }
 
-func (r *StatisticsRepo) GetPRStats(ctx context.Context) (*PRStats, error) {
+func (r *StatisticsRepo) GetPRStats(ctx context.Context, teamIDs []uuid.UUID, window Window) (*PRStats, error) {
+	filter, teamArgs := teamFilter("author_id", teamIDs)
+	args := append(windowArgs(window), teamArgs...)
 	query := `
 		SELECT 
-			COUNT(*) as total_prs,
-			COUNT(*) FILTER (WHERE status = 'OPEN') as open_prs,
-			COUNT(*) FILTER (WHERE status = 'MERGED') as merged_prs,
-			(SELECT COUNT(*) FROM pr_reviewers) as total_assignments
+			COUNT(*) FILTER (WHERE created_at >= $1 AND created_at < $2) as total_prs,
+			COUNT(*) FILTER (WHERE created_at >= $1 AND created_at < $2 AND status = 'OPEN') as open_prs,
+			COUNT(*) FILTER (WHERE merged_at >= $1 AND merged_at < $2) as merged_prs,
+			(SELECT COUNT(*) FROM pr_reviewers WHERE assigned_at >= $1 AND assigned_at < $2 AND pull_request_id IN (
+				SELECT pull_request_id FROM pull_requests WHERE ` + filter + `
+			)) as total_assignments
 		FROM pull_requests
+		WHERE ` + filter + `
 	`
 
 	var stats PRStats
-	err := r.db.QueryRowContext(ctx, query).Scan(
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
 		&stats.TotalPRs,
 		&stats.OpenPRs,
 		&stats.MergedPRs,
@@ -85,3 +117,95 @@ func (r *StatisticsRepo) GetPRStats(ctx context.Context) (*PRStats, error) {
 	return &stats, nil
 }
 
+// TeamStats aggregates one team over a window.
+type TeamStats struct {
+	TeamName          string
+	OpenedPRs         int
+	OpenPRs           int
+	MergedPRs         int
+	Assignments       int
+	AvgReviewersPerPR float64
+	Members           []UserAssignmentStats
+}
+
+// teamStatsFilter selects the team named $3, or every live team when $3 is empty.
+const teamStatsFilter = `(t.team_name = $3 OR ($3 = '' AND t.archived_at IS NULL))`
+
+func (r *StatisticsRepo) GetTeamStats(ctx context.Context, teamName string, window Window) ([]TeamStats, error) {
+	args := append(windowArgs(window), teamName)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			t.team_name,
+			COUNT(pr.pull_request_id) FILTER (WHERE pr.created_at >= $1 AND pr.created_at < $2) as opened_prs,
+			COUNT(pr.pull_request_id) FILTER (WHERE pr.created_at >= $1 AND pr.created_at < $2 AND pr.status = 'OPEN') as open_prs,
+			COUNT(pr.pull_request_id) FILTER (WHERE pr.merged_at >= $1 AND pr.merged_at < $2) as merged_prs,
+			COALESCE(AVG((
+				SELECT COUNT(*) FROM pr_reviewers rev WHERE rev.pull_request_id = pr.pull_request_id
+			)) FILTER (WHERE pr.created_at >= $1 AND pr.created_at < $2), 0) as avg_reviewers
+		FROM teams t
+		LEFT JOIN team_members m ON m.team_id = t.team_id
+		LEFT JOIN pull_requests pr ON pr.author_id = m.user_id
+		WHERE `+teamStatsFilter+`
+		GROUP BY t.team_name
+		ORDER BY t.team_name
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query team stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []TeamStats{}
+	index := map[string]int{}
+	for rows.Next() {
+		var stat TeamStats
+		if err := rows.Scan(&stat.TeamName, &stat.OpenedPRs, &stat.OpenPRs, &stat.MergedPRs, &stat.AvgReviewersPerPR); err != nil {
+			return nil, fmt.Errorf("scan team stat: %w", err)
+		}
+		stat.Members = []UserAssignmentStats{}
+		index[stat.TeamName] = len(stats)
+		stats = append(stats, stat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	memberRows, err := r.db.QueryContext(ctx, `
+		SELECT
+			t.team_name,
+			u.user_id::text,
+			u.username,
+			COUNT(rev.reviewer_id) as assignment_count
+		FROM teams t
+		JOIN team_members m ON m.team_id = t.team_id
+		JOIN users u ON u.user_id = m.user_id
+		LEFT JOIN pr_reviewers rev ON rev.reviewer_id = u.user_id
+			AND rev.assigned_at >= $1 AND rev.assigned_at < $2
+		WHERE `+teamStatsFilter+`
+		GROUP BY t.team_name, u.user_id, u.username
+		ORDER BY t.team_name, assignment_count DESC, u.username
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query team member stats: %w", err)
+	}
+	defer memberRows.Close()
+
+	for memberRows.Next() {
+		var teamName string
+		var member UserAssignmentStats
+		if err := memberRows.Scan(&teamName, &member.UserID, &member.Username, &member.Count); err != nil {
+			return nil, fmt.Errorf("scan team member stat: %w", err)
+		}
+		i, ok := index[teamName]
+		if !ok {
+			continue
+		}
+		stats[i].Members = append(stats[i].Members, member)
+		stats[i].Assignments += member.Count
+	}
+	if err := memberRows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return stats, nil
+}
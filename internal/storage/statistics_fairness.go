@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+type MemberLoad struct {
+	TeamName    string
+	UserID      string
+	Username    string
+	Assignments int
+	ActiveDays  float64
+}
+
+// GetMemberLoads counts as ActiveDays the days a member was active and in the team.
+func (r *StatisticsRepo) GetMemberLoads(ctx context.Context, teamName string, window Window) ([]MemberLoad, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH spans AS (
+			SELECT user_id, is_active,
+			       GREATEST(changed_at, $1) as since,
+			       LEAD(GREATEST(changed_at, $1), 1, $2::timestamptz)
+			           OVER (PARTITION BY user_id ORDER BY changed_at) as until
+			FROM user_activity_log
+			WHERE changed_at < $2
+		)
+		SELECT
+			t.team_name,
+			u.user_id::text,
+			u.username,
+			(
+				SELECT COUNT(*) FROM pr_reviewers rev
+				WHERE rev.reviewer_id = u.user_id AND rev.assigned_at >= $1 AND rev.assigned_at < $2
+			) as assignment_count,
+			COALESCE((
+				SELECT SUM(EXTRACT(EPOCH FROM GREATEST(s.until - GREATEST(s.since, m.joined_at), interval '0')))
+				FROM spans s
+				WHERE s.user_id = u.user_id AND s.is_active
+			), 0)::float8 / 86400 as active_days
+		FROM teams t
+		JOIN team_members m ON m.team_id = t.team_id
+		JOIN users u ON u.user_id = m.user_id
+		WHERE `+teamStatsFilter+` AND m.role <> 'observer'
+		ORDER BY t.team_name, u.username, u.user_id
+	`, append(windowArgs(window), teamName)...)
+	if err != nil {
+		return nil, fmt.Errorf("query member loads: %w", err)
+	}
+	defer rows.Close()
+
+	loads := []MemberLoad{}
+	for rows.Next() {
+		var load MemberLoad
+		if err := rows.Scan(&load.TeamName, &load.UserID, &load.Username, &load.Assignments, &load.ActiveDays); err != nil {
+			return nil, fmt.Errorf("scan member load: %w", err)
+		}
+		loads = append(loads, load)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return loads, nil
+}
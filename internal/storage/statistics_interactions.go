@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+type Interaction struct {
+	TeamName     string
+	AuthorID     string
+	AuthorName   string
+	ReviewerID   string
+	ReviewerName string
+	Reviews      int
+}
+
+func (r *StatisticsRepo) GetInteractions(ctx context.Context, teamName string, window Window) ([]Interaction, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			t.team_name,
+			a.user_id::text,
+			a.username,
+			u.user_id::text,
+			u.username,
+			COUNT(*) as reviews
+		FROM teams t
+		JOIN team_members m ON m.team_id = t.team_id
+		JOIN pull_requests p ON p.author_id = m.user_id
+		JOIN pr_reviewers rev ON rev.pull_request_id = p.pull_request_id
+		JOIN users a ON a.user_id = p.author_id
+		JOIN users u ON u.user_id = rev.reviewer_id
+		WHERE `+teamStatsFilter+`
+			AND rev.assigned_at >= $1 AND rev.assigned_at < $2
+		GROUP BY t.team_name, a.user_id, a.username, u.user_id, u.username
+		ORDER BY t.team_name, a.username, u.username
+	`, append(windowArgs(window), teamName)...)
+	if err != nil {
+		return nil, fmt.Errorf("query interactions: %w", err)
+	}
+	defer rows.Close()
+
+	interactions := []Interaction{}
+	for rows.Next() {
+		var i Interaction
+		if err := rows.Scan(&i.TeamName, &i.AuthorID, &i.AuthorName, &i.ReviewerID, &i.ReviewerName, &i.Reviews); err != nil {
+			return nil, fmt.Errorf("scan interaction: %w", err)
+		}
+		interactions = append(interactions, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return interactions, nil
+}
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Percentiles summarises durations in seconds. They are zero when Count is.
+type Percentiles struct {
+	Count int
+	P50   float64
+	P90   float64
+	P99   float64
+}
+
+// LatencyStats are the latencies of PRs created in a window.
+type LatencyStats struct {
+	ToAssignment Percentiles
+	ToVerdict    Percentiles
+	ToMerge      Percentiles
+}
+
+type TeamLatency struct {
+	TeamName string
+	LatencyStats
+}
+
+type ReviewerLatency struct {
+	UserID   string
+	Username string
+	LatencyStats
+}
+
+// latencyPRs has one row per PR created in the window. first_assigned_at is
+// kept on the PR because reassignments reset pr_reviewers.assigned_at.
+const latencyPRs = `
+		WITH prs AS (
+			SELECT pr.pull_request_id, pr.author_id, pr.created_at, pr.merged_at, pr.first_assigned_at,
+			       (SELECT MIN(r.decided_at) FROM pr_reviewers r
+			        WHERE r.pull_request_id = pr.pull_request_id) as first_verdict_at
+			FROM pull_requests pr
+			WHERE pr.created_at >= $1 AND pr.created_at < $2
+		)
+	`
+
+// latencyTeamFilter keeps everything when $3 is empty.
+func latencyTeamFilter(userColumn string) string {
+	return `($3 = '' OR ` + userColumn + ` IN (
+			SELECT fm.user_id FROM team_members fm
+			JOIN teams ft ON ft.team_id = fm.team_id
+			WHERE ft.team_name = $3
+		))`
+}
+
+func latencyColumns(intervals ...string) string {
+	columns := make([]string, 0, len(intervals))
+	for _, interval := range intervals {
+		columns = append(columns, fmt.Sprintf(
+			"COUNT(%[1]s), percentile_cont(ARRAY[0.5, 0.9, 0.99]) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM %[1]s)::float8)",
+			interval,
+		))
+	}
+	return strings.Join(columns, ",\n\t\t\t")
+}
+
+type latencyScan struct {
+	counts      [3]int
+	percentiles [3]pq.Float64Array
+}
+
+func (s *latencyScan) dest() []interface{} {
+	return []interface{}{
+		&s.counts[0], &s.percentiles[0],
+		&s.counts[1], &s.percentiles[1],
+		&s.counts[2], &s.percentiles[2],
+	}
+}
+
+func (s *latencyScan) stats() LatencyStats {
+	var result [3]Percentiles
+	for i := range result {
+		result[i].Count = s.counts[i]
+		if len(s.percentiles[i]) == 3 {
+			result[i].P50 = s.percentiles[i][0]
+			result[i].P90 = s.percentiles[i][1]
+			result[i].P99 = s.percentiles[i][2]
+		}
+	}
+	return LatencyStats{ToAssignment: result[0], ToVerdict: result[1], ToMerge: result[2]}
+}
+
+var prLatencyColumns = latencyColumns(
+	"prs.first_assigned_at - prs.created_at",
+	"prs.first_verdict_at - prs.created_at",
+	"prs.merged_at - prs.created_at",
+)
+
+var reviewerLatencyColumns = latencyColumns(
+	"rev.assigned_at - prs.created_at",
+	"rev.decided_at - rev.assigned_at",
+	"prs.merged_at - prs.created_at",
+)
+
+func (r *StatisticsRepo) GetLatencyStats(ctx context.Context, teamName string, window Window) (*LatencyStats, error) {
+	var scan latencyScan
+	err := r.db.QueryRowContext(ctx, latencyPRs+`
+		SELECT `+prLatencyColumns+`
+		FROM prs
+		WHERE `+latencyTeamFilter("prs.author_id")+`
+	`, append(windowArgs(window), teamName)...).Scan(scan.dest()...)
+	if err != nil {
+		return nil, fmt.Errorf("query latency stats: %w", err)
+	}
+
+	stats := scan.stats()
+	return &stats, nil
+}
+
+func (r *StatisticsRepo) GetTeamLatencyStats(ctx context.Context, teamName string, window Window) ([]TeamLatency, error) {
+	rows, err := r.db.QueryContext(ctx, latencyPRs+`
+		SELECT t.team_name,
+			`+prLatencyColumns+`
+		FROM prs
+		JOIN team_members m ON m.user_id = prs.author_id
+		JOIN teams t ON t.team_id = m.team_id
+		WHERE `+teamStatsFilter+`
+		GROUP BY t.team_name
+		ORDER BY t.team_name
+	`, append(windowArgs(window), teamName)...)
+	if err != nil {
+		return nil, fmt.Errorf("query team latency stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []TeamLatency{}
+	for rows.Next() {
+		var stat TeamLatency
+		var scan latencyScan
+		if err := rows.Scan(append([]interface{}{&stat.TeamName}, scan.dest()...)...); err != nil {
+			return nil, fmt.Errorf("scan team latency stat: %w", err)
+		}
+		stat.LatencyStats = scan.stats()
+		stats = append(stats, stat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return stats, nil
+}
+
+func (r *StatisticsRepo) GetReviewerLatencyStats(ctx context.Context, teamName string, window Window) ([]ReviewerLatency, error) {
+	rows, err := r.db.QueryContext(ctx, latencyPRs+`
+		SELECT u.user_id::text, u.username,
+			`+reviewerLatencyColumns+`
+		FROM prs
+		JOIN pr_reviewers rev ON rev.pull_request_id = prs.pull_request_id
+		JOIN users u ON u.user_id = rev.reviewer_id
+		WHERE `+latencyTeamFilter("u.user_id")+`
+		GROUP BY u.user_id, u.username
+		ORDER BY u.username, u.user_id
+	`, append(windowArgs(window), teamName)...)
+	if err != nil {
+		return nil, fmt.Errorf("query reviewer latency stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []ReviewerLatency{}
+	for rows.Next() {
+		var stat ReviewerLatency
+		var scan latencyScan
+		if err := rows.Scan(append([]interface{}{&stat.UserID, &stat.Username}, scan.dest()...)...); err != nil {
+			return nil, fmt.Errorf("scan reviewer latency stat: %w", err)
+		}
+		stat.LatencyStats = scan.stats()
+		stats = append(stats, stat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return stats, nil
+}
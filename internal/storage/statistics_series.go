@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SeriesFilter struct {
+	TeamName string
+	UserID   uuid.NullUUID
+}
+
+// SeriesPoint holds the counts of one bucket starting at Start.
+type SeriesPoint struct {
+	Start       time.Time
+	OpenedPRs   int
+	MergedPRs   int
+	Assignments int
+}
+
+// seriesFilter applies the SeriesFilter arguments $5 and $6 to userColumn.
+func seriesFilter(userColumn string) string {
+	return `($5 = '' OR ` + userColumn + ` IN (
+				SELECT fm.user_id FROM team_members fm
+				JOIN teams ft ON ft.team_id = fm.team_id
+				WHERE ft.team_name = $5
+			))
+			AND ($6::uuid IS NULL OR ` + userColumn + ` = $6)`
+}
+
+func (r *StatisticsRepo) GetSeries(ctx context.Context, window Window, bucket, timeZone string, filter SeriesFilter) ([]SeriesPoint, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH buckets AS (
+			SELECT b as bucket
+			FROM generate_series(
+				date_trunc($3, $1::timestamptz AT TIME ZONE $4),
+				$2::timestamptz AT TIME ZONE $4,
+				('1 ' || $3)::interval
+			) as b
+			WHERE b < $2::timestamptz AT TIME ZONE $4
+		),
+		opened AS (
+			SELECT date_trunc($3, created_at AT TIME ZONE $4) as bucket, COUNT(*) as n
+			FROM pull_requests
+			WHERE created_at >= $1 AND created_at < $2 AND `+seriesFilter("author_id")+`
+			GROUP BY 1
+		),
+		merged AS (
+			SELECT date_trunc($3, merged_at AT TIME ZONE $4) as bucket, COUNT(*) as n
+			FROM pull_requests
+			WHERE merged_at >= $1 AND merged_at < $2 AND `+seriesFilter("author_id")+`
+			GROUP BY 1
+		),
+		assigned AS (
+			SELECT date_trunc($3, assigned_at AT TIME ZONE $4) as bucket, COUNT(*) as n
+			FROM pr_reviewers
+			WHERE assigned_at >= $1 AND assigned_at < $2 AND `+seriesFilter("reviewer_id")+`
+			GROUP BY 1
+		)
+		SELECT b.bucket AT TIME ZONE $4, COALESCE(o.n, 0), COALESCE(m.n, 0), COALESCE(a.n, 0)
+		FROM buckets b
+		LEFT JOIN opened o ON o.bucket = b.bucket
+		LEFT JOIN merged m ON m.bucket = b.bucket
+		LEFT JOIN assigned a ON a.bucket = b.bucket
+		ORDER BY b.bucket
+	`, window.From, window.To, bucket, timeZone, filter.TeamName, filter.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("query series: %w", err)
+	}
+	defer rows.Close()
+
+	points := []SeriesPoint{}
+	for rows.Next() {
+		var point SeriesPoint
+		if err := rows.Scan(&point.Start, &point.OpenedPRs, &point.MergedPRs, &point.Assignments); err != nil {
+			return nil, fmt.Errorf("scan series point: %w", err)
+		}
+		points = append(points, point)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return points, nil
+}
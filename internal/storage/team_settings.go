@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reviewer-service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type TeamSettingsRepo struct {
+	db *sql.DB
+}
+
+func NewTeamSettingsRepo(db *sql.DB) *TeamSettingsRepo {
+	return &TeamSettingsRepo{db: db}
+}
+
+func (r *TeamSettingsRepo) GetTeamSettings(ctx context.Context, teamID uuid.UUID) (*models.TeamSettingsVersion, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT team_id, version, settings, created_at
+		FROM team_settings
+		WHERE team_id = $1
+		ORDER BY version DESC
+		LIMIT 1
+	`, teamID)
+
+	v, err := scanTeamSettings(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query team settings: %w", err)
+	}
+	return v, nil
+}
+
+func (r *TeamSettingsRepo) GetTeamSettingsHistory(ctx context.Context, teamID uuid.UUID) ([]models.TeamSettingsVersion, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT team_id, version, settings, created_at
+		FROM team_settings
+		WHERE team_id = $1
+		ORDER BY version DESC
+	`, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("query team settings: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []models.TeamSettingsVersion
+	for rows.Next() {
+		v, err := scanTeamSettings(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan team settings: %w", err)
+		}
+		versions = append(versions, *v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// SaveTeamSettings stores settings as the next version.
+func (r *TeamSettingsRepo) SaveTeamSettings(ctx context.Context, teamID uuid.UUID, expectedVersion int, settings models.TeamSettings) (*models.TeamSettingsVersion, error) {
+	doc, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("marshal team settings: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var locked uuid.UUID
+	err = tx.QueryRowContext(ctx, "SELECT team_id FROM teams WHERE team_id = $1 FOR UPDATE", teamID).Scan(&locked)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrTeamNotFound
+		}
+		return nil, fmt.Errorf("lock team: %w", err)
+	}
+
+	var current int
+	err = tx.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM team_settings WHERE team_id = $1", teamID).Scan(&current)
+	if err != nil {
+		return nil, fmt.Errorf("query settings version: %w", err)
+	}
+	if expectedVersion >= 0 && expectedVersion != current {
+		return nil, models.ErrSettingsVersionConflict
+	}
+
+	row := tx.QueryRowContext(ctx, `
+		INSERT INTO team_settings (team_id, version, settings)
+		VALUES ($1, $2, $3::jsonb)
+		RETURNING team_id, version, settings, created_at
+	`, teamID, current+1, string(doc))
+
+	v, err := scanTeamSettings(row)
+	if err != nil {
+		return nil, fmt.Errorf("insert team settings: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func scanTeamSettings(row rowScanner) (*models.TeamSettingsVersion, error) {
+	var v models.TeamSettingsVersion
+	var teamID uuid.UUID
+	var doc []byte
+	if err := row.Scan(&teamID, &v.Version, &doc, &v.CreatedAt); err != nil {
+		return nil, err
+	}
+	v.TeamID = teamID.String()
+	v.Settings = models.DefaultTeamSettings()
+	if err := json.Unmarshal(doc, &v.Settings); err != nil {
+		return nil, fmt.Errorf("decode team settings: %w", err)
+	}
+	return &v, nil
+}
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reviewer-service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func (r *TeamsRepo) SyncTeams(ctx context.Context, actions []models.TeamSyncAction, reassignments []models.ReviewerReassignment) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var touched []uuid.UUID
+	for _, action := range actions {
+		var userID uuid.UUID
+		if action.UserID != "" {
+			userID, err = uuid.Parse(action.UserID)
+			if err != nil {
+				return fmt.Errorf("invalid user_id %s: %w", action.UserID, err)
+			}
+			touched = append(touched, userID)
+		}
+
+		if err := applySyncAction(ctx, tx, action, userID); err != nil {
+			return err
+		}
+	}
+
+	if err := ensurePrimary(ctx, tx, touched); err != nil {
+		return err
+	}
+
+	if err := applyReassignments(ctx, tx, reassignments); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func applySyncAction(ctx context.Context, tx *sql.Tx, action models.TeamSyncAction, userID uuid.UUID) error {
+	switch action.Kind {
+	case models.TeamSyncCreateTeam:
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO teams (team_name, parent_team_id)
+			VALUES ($1, (SELECT team_id FROM teams WHERE team_name = $2))
+		`, action.TeamName, action.ParentTeam)
+		if err != nil {
+			return fmt.Errorf("insert team %s: %w", action.TeamName, err)
+		}
+
+	case models.TeamSyncUpdateTeam:
+		teamID, err := lockTeam(ctx, tx, action.TeamName)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE teams
+			SET parent_team_id = (SELECT team_id FROM teams WHERE team_name = $2)
+			WHERE team_id = $1
+		`, teamID, action.ParentTeam)
+		if err != nil {
+			return fmt.Errorf("set parent of %s: %w", action.TeamName, err)
+		}
+
+	case models.TeamSyncCreateUser, models.TeamSyncUpdateUser:
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO users (user_id, username, is_active)
+			VALUES ($1, $2, true)
+			ON CONFLICT (user_id) DO UPDATE SET username = EXCLUDED.username, is_active = true
+			WHERE users.erased_at IS NULL
+		`, userID, action.Username)
+		if err != nil {
+			return fmt.Errorf("upsert user %s: %w", action.UserID, err)
+		}
+
+	case models.TeamSyncAddMember:
+		teamID, err := lockTeam(ctx, tx, action.TeamName)
+		if err != nil {
+			return err
+		}
+		return addMembership(ctx, tx, teamID, userID, action.Role)
+
+	case models.TeamSyncSetRole:
+		res, err := tx.ExecContext(ctx, `
+			UPDATE team_members m
+			SET role = $3
+			FROM teams t
+			WHERE t.team_id = m.team_id AND t.team_name = $1 AND m.user_id = $2
+		`, action.TeamName, userID, action.Role)
+		if err != nil {
+			return fmt.Errorf("set role: %w", err)
+		}
+		return requireAffected(res, models.ErrNotTeamMember)
+
+	case models.TeamSyncMoveMember:
+		teamID, err := lockTeam(ctx, tx, action.TeamName)
+		if err != nil {
+			return err
+		}
+		return moveMembership(ctx, tx, userID, action.FromTeam, teamID, action.Role)
+
+	case models.TeamSyncRemoveMember:
+		res, err := tx.ExecContext(ctx, `
+			DELETE FROM team_members
+			WHERE user_id = $1 AND team_id = (SELECT team_id FROM teams WHERE team_name = $2)
+		`, userID, action.TeamName)
+		if err != nil {
+			return fmt.Errorf("remove member: %w", err)
+		}
+		return requireAffected(res, models.ErrNotTeamMember)
+
+	case models.TeamSyncDeactivateUser:
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET is_active = false WHERE user_id = $1", userID); err != nil {
+			return fmt.Errorf("deactivate user %s: %w", action.UserID, err)
+		}
+
+	default:
+		return fmt.Errorf("unknown sync action %s", action.Kind)
+	}
+	return nil
+}
+
+func requireAffected(res sql.Result, notFound error) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
@@ -3,10 +3,12 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"reviewer-service/internal/models"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type TeamsRepo struct {
@@ -30,38 +32,480 @@ func (r *TeamsRepo) CreateTeam(ctx context.Context, team models.Team) error {
 		return fmt.Errorf("check team exists: %w", err)
 	}
 	if exists {
-		return fmt.Errorf("team_name already exists")
+		return models.ErrTeamExists
 	}
 
-	_, err = tx.ExecContext(ctx, "INSERT INTO teams (team_name) VALUES ($1)", team.Name)
+	var parentID interface{}
+	if team.ParentID != "" {
+		parentID = team.ParentID
+	}
+
+	var teamID uuid.UUID
+	err = tx.QueryRowContext(ctx, "INSERT INTO teams (team_name, parent_team_id) VALUES ($1, $2) RETURNING team_id", team.Name, parentID).Scan(&teamID)
 	if err != nil {
 		return fmt.Errorf("insert team: %w", err)
 	}
 
 	for _, u := range team.Members {
-		userID, err := uuid.Parse(u.ID)
+		if err := upsertMember(ctx, tx, teamID, u); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *TeamsRepo) AddUsersToTeam(ctx context.Context, teamName string, users []models.User) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	teamID, err := lockTeam(ctx, tx, teamName)
+	if err != nil {
+		return err
+	}
+
+	for _, u := range users {
+		if err := upsertMember(ctx, tx, teamID, u); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *TeamsRepo) RemoveUserFromTeam(ctx context.Context, teamName string, userID uuid.UUID, deactivate bool, reassignments []models.ReviewerReassignment) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		DELETE FROM team_members
+		WHERE user_id = $1 AND team_id = (SELECT team_id FROM teams WHERE team_name = $2)
+	`, userID, teamName)
+	if err != nil {
+		return fmt.Errorf("remove member: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrNotTeamMember
+	}
+
+	if err := ensurePrimary(ctx, tx, []uuid.UUID{userID}); err != nil {
+		return err
+	}
+
+	if deactivate {
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET is_active = false WHERE user_id = $1", userID); err != nil {
+			return fmt.Errorf("deactivate member: %w", err)
+		}
+	}
+
+	if err := applyReassignments(ctx, tx, reassignments); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *TeamsRepo) MoveUserToTeam(ctx context.Context, userID uuid.UUID, fromTeam, toTeam string, reassignments []models.ReviewerReassignment) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	toTeamID, err := lockTeam(ctx, tx, toTeam)
+	if err != nil {
+		return err
+	}
+
+	if err := moveMembership(ctx, tx, userID, fromTeam, toTeamID, models.TeamRoleMember); err != nil {
+		return err
+	}
+
+	if err := applyReassignments(ctx, tx, reassignments); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func moveMembership(ctx context.Context, tx *sql.Tx, userID uuid.UUID, fromTeam string, toTeamID uuid.UUID, role models.TeamRole) error {
+	var wasPrimary bool
+	err := tx.QueryRowContext(ctx, `
+		DELETE FROM team_members
+		WHERE user_id = $1 AND team_id = (SELECT team_id FROM teams WHERE team_name = $2)
+		RETURNING is_primary
+	`, userID, fromTeam).Scan(&wasPrimary)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotTeamMember
+		}
+		return fmt.Errorf("move member: %w", err)
+	}
+
+	if err := addMembership(ctx, tx, toTeamID, userID, role); err != nil {
+		return err
+	}
+	if wasPrimary {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE team_members
+			SET is_primary = (team_id = $2)
+			WHERE user_id = $1
+		`, userID, toTeamID)
 		if err != nil {
-			return fmt.Errorf("invalid user_id %s: %w", u.ID, err)
+			return fmt.Errorf("set primary team: %w", err)
 		}
+	}
+	return nil
+}
+
+func upsertMember(ctx context.Context, tx *sql.Tx, teamID uuid.UUID, u models.User) error {
+	userID, err := uuid.Parse(u.ID)
+	if err != nil {
+		return fmt.Errorf("invalid user_id %s: %w", u.ID, err)
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO users (user_id, username, is_active)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET username = EXCLUDED.username, is_active = EXCLUDED.is_active
+		WHERE users.erased_at IS NULL
+	`, userID, u.Username, u.IsActive)
+	if err != nil {
+		return fmt.Errorf("insert user %s: %w", u.ID, err)
+	}
+	return addMembership(ctx, tx, teamID, userID, u.Role)
+}
+
+func addMembership(ctx context.Context, tx *sql.Tx, teamID, userID uuid.UUID, role models.TeamRole) error {
+	if role == "" {
+		role = models.TeamRoleMember
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO team_members (team_id, user_id, is_primary, role)
+		VALUES ($1, $2, NOT EXISTS (SELECT 1 FROM team_members WHERE user_id = $2 AND is_primary), $3)
+		ON CONFLICT (team_id, user_id) DO NOTHING
+	`, teamID, userID, role)
+	if err != nil {
+		return fmt.Errorf("add membership %s: %w", userID, err)
+	}
+	return nil
+}
+
+func ensurePrimary(ctx context.Context, tx *sql.Tx, userIDs []uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE team_members m
+		SET is_primary = true
+		WHERE m.user_id = ANY($1::uuid[])
+		  AND NOT EXISTS (SELECT 1 FROM team_members p WHERE p.user_id = m.user_id AND p.is_primary)
+		  AND m.team_id = (
+			  SELECT f.team_id
+			  FROM team_members f
+			  WHERE f.user_id = m.user_id
+			  ORDER BY f.joined_at, f.team_id
+			  LIMIT 1
+		  )
+	`, uuidArray(userIDs))
+	if err != nil {
+		return fmt.Errorf("set primary team: %w", err)
+	}
+	return nil
+}
+
+// ArchiveTeam deactivates op.UserIDs, applies the reassignments and marks the
+// team archived while holding the team row lock. It fails with
+// models.ErrTeamChanged when the active exclusive members are no longer
+// op.UserIDs.
+func (r *TeamsRepo) ArchiveTeam(ctx context.Context, teamName string, op models.BulkOperation, reassignments []models.ReviewerReassignment) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	teamID, err := lockTeam(ctx, tx, teamName)
+	if err != nil {
+		return err
+	}
+
+	members, err := exclusiveMembers(ctx, tx, teamName, true)
+	if err != nil {
+		return err
+	}
+	if !sameMembers(members, op.UserIDs) {
+		return models.ErrTeamChanged
+	}
+
+	if err := applyReassignments(ctx, tx, reassignments); err != nil {
+		return err
+	}
+
+	if len(op.UserIDs) > 0 {
 		_, err = tx.ExecContext(ctx, `
-            INSERT INTO users (user_id, username, team_name, is_active)
-            VALUES ($1, $2, $3, $4)
-            ON CONFLICT (user_id) DO UPDATE SET username = EXCLUDED.username, team_name = EXCLUDED.team_name, is_active = EXCLUDED.is_active
-        `, userID, u.Username, team.Name, u.IsActive)
+			UPDATE users
+			SET is_active = false
+			WHERE user_id = ANY($1::uuid[]) AND is_active = true
+		`, stringArray(op.UserIDs))
 		if err != nil {
-			return fmt.Errorf("insert user %s: %w", u.ID, err)
+			return fmt.Errorf("deactivate members: %w", err)
 		}
+
+		op.Reassignments = reassignments
+		if err := insertBulkOperation(ctx, tx, op); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE teams SET archived_at = now() WHERE team_id = $1", teamID); err != nil {
+		return fmt.Errorf("archive team: %w", err)
 	}
 
 	return tx.Commit()
 }
 
+func sameMembers(ids []uuid.UUID, userIDs []string) bool {
+	if len(ids) != len(userIDs) {
+		return false
+	}
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id.String()] = true
+	}
+	for _, id := range userIDs {
+		if !set[id] {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *TeamsRepo) GetOpenPullRequestIDs(ctx context.Context, teamName string) ([]string, error) {
+	members, err := r.GetExclusiveMembers(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT pr.pull_request_id
+		FROM pull_requests pr
+		WHERE pr.status = 'OPEN'
+		  AND (
+			  pr.author_id = ANY($1::uuid[])
+			  OR EXISTS (
+				  SELECT 1
+				  FROM pr_reviewers rev
+				  WHERE rev.pull_request_id = pr.pull_request_id AND rev.reviewer_id = ANY($1::uuid[])
+			  )
+		  )
+		ORDER BY pr.pull_request_id
+	`, uuidArray(members))
+	if err != nil {
+		return nil, fmt.Errorf("query open PRs: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan PR: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// DeleteTeam deactivates the members that belong to no other active team.
+// When targetTeam is set, such members who author open PRs are moved there
+// instead; the IDs of those PRs are returned.
+func (r *TeamsRepo) DeleteTeam(ctx context.Context, teamName, targetTeam string, reassignments []models.ReviewerReassignment) ([]string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var teamID uuid.UUID
+	err = tx.QueryRowContext(ctx, "SELECT team_id FROM teams WHERE team_name = $1 FOR UPDATE", teamName).Scan(&teamID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrTeamNotFound
+		}
+		return nil, fmt.Errorf("lock team: %w", err)
+	}
+
+	movedPRs := []string{}
+	if targetTeam != "" {
+		movedPRs, err = moveAuthors(ctx, tx, teamName, targetTeam)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyReassignments(ctx, tx, reassignments); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT user_id FROM team_members WHERE team_id = $1", teamID)
+	if err != nil {
+		return nil, fmt.Errorf("query members: %w", err)
+	}
+	var memberIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan member: %w", err)
+		}
+		memberIDs = append(memberIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users u
+		SET is_active = false
+		WHERE u.user_id = ANY($1::uuid[])
+		  AND NOT EXISTS (
+			  SELECT 1
+			  FROM team_members m
+			  JOIN teams t ON t.team_id = m.team_id
+			  WHERE m.user_id = u.user_id AND m.team_id <> $2 AND t.archived_at IS NULL
+		  )
+	`, uuidArray(memberIDs), teamID)
+	if err != nil {
+		return nil, fmt.Errorf("deactivate members: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM teams WHERE team_id = $1", teamID); err != nil {
+		return nil, fmt.Errorf("delete team: %w", err)
+	}
+
+	if err := ensurePrimary(ctx, tx, memberIDs); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return movedPRs, nil
+}
+
+// moveAuthors moves the exclusive members of teamName who author open PRs to
+// targetTeam and returns the IDs of those PRs.
+func moveAuthors(ctx context.Context, tx *sql.Tx, teamName, targetTeam string) ([]string, error) {
+	targetID, err := lockTeam(ctx, tx, targetTeam)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := exclusiveMembers(ctx, tx, teamName, false)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT pull_request_id, author_id
+		FROM pull_requests
+		WHERE status = 'OPEN' AND author_id = ANY($1::uuid[])
+		ORDER BY pull_request_id
+	`, uuidArray(members))
+	if err != nil {
+		return nil, fmt.Errorf("query authored PRs: %w", err)
+	}
+	prIDs := []string{}
+	var authors []uuid.UUID
+	seen := map[uuid.UUID]bool{}
+	for rows.Next() {
+		var prID string
+		var authorID uuid.UUID
+		if err := rows.Scan(&prID, &authorID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan PR: %w", err)
+		}
+		prIDs = append(prIDs, prID)
+		if !seen[authorID] {
+			seen[authorID] = true
+			authors = append(authors, authorID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, id := range authors {
+		if err := moveMembership(ctx, tx, id, teamName, targetID, models.TeamRoleMember); err != nil {
+			return nil, err
+		}
+	}
+	return prIDs, nil
+}
+
+func lockTeam(ctx context.Context, tx *sql.Tx, teamName string) (uuid.UUID, error) {
+	var teamID uuid.UUID
+	var archivedAt sql.NullTime
+	err := tx.QueryRowContext(ctx, "SELECT team_id, archived_at FROM teams WHERE team_name = $1 FOR UPDATE", teamName).Scan(&teamID, &archivedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return uuid.Nil, models.ErrTeamNotFound
+		}
+		return uuid.Nil, fmt.Errorf("lock team: %w", err)
+	}
+	if archivedAt.Valid {
+		return uuid.Nil, models.ErrTeamArchived
+	}
+	return teamID, nil
+}
+
 func (r *TeamsRepo) GetTeamByName(ctx context.Context, name string) (*models.Team, error) {
+	return r.getTeam(ctx, "team_name", name)
+}
+
+func (r *TeamsRepo) GetTeamByID(ctx context.Context, id uuid.UUID) (*models.Team, error) {
+	return r.getTeam(ctx, "team_id", id)
+}
+
+func (r *TeamsRepo) getTeam(ctx context.Context, column string, value interface{}) (*models.Team, error) {
+	var teamID uuid.UUID
+	var name string
+	var parentID uuid.NullUUID
+	var labels pq.StringArray
+	var archivedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+		SELECT team_id, team_name, parent_team_id, lead_review_labels, archived_at
+		FROM teams
+		WHERE `+column+` = $1
+	`, value).Scan(&teamID, &name, &parentID, &labels, &archivedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrTeamNotFound
+		}
+		return nil, fmt.Errorf("query team: %w", err)
+	}
+
 	rows, err := r.db.QueryContext(ctx, `
-        SELECT user_id, username, is_active
-        FROM users
-        WHERE team_name = $1
-    `, name)
+		SELECT u.user_id, u.username, u.is_active, m.role
+		FROM users u
+		JOIN team_members m ON m.user_id = u.user_id
+		WHERE m.team_id = $1
+		ORDER BY m.joined_at, u.user_id
+	`, teamID)
 	if err != nil {
 		return nil, fmt.Errorf("query users: %w", err)
 	}
@@ -71,7 +515,7 @@ func (r *TeamsRepo) GetTeamByName(ctx context.Context, name string) (*models.Tea
 	for rows.Next() {
 		var u models.User
 		var userID uuid.UUID
-		if err := rows.Scan(&userID, &u.Username, &u.IsActive); err != nil {
+		if err := rows.Scan(&userID, &u.Username, &u.IsActive, &u.Role); err != nil {
 			return nil, fmt.Errorf("scan user: %w", err)
 		}
 		u.ID = userID.String()
@@ -83,8 +527,370 @@ func (r *TeamsRepo) GetTeamByName(ctx context.Context, name string) (*models.Tea
 		return nil, err
 	}
 
-	return &models.Team{
-		Name:    name,
-		Members: members,
-	}, nil
+	team := &models.Team{
+		ID:               teamID.String(),
+		Name:             name,
+		LeadReviewLabels: []string(labels),
+		Members:          members,
+	}
+	if parentID.Valid {
+		team.ParentID = parentID.UUID.String()
+	}
+	if archivedAt.Valid {
+		team.ArchivedAt = &archivedAt.Time
+	}
+
+	return team, nil
+}
+
+// GetAllTeams returns every team with its members, ordered by team name.
+func (r *TeamsRepo) GetAllTeams(ctx context.Context) ([]models.Team, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT t.team_id, t.team_name, t.parent_team_id, t.lead_review_labels, t.archived_at,
+			   u.user_id, u.username, u.is_active, m.role
+		FROM teams t
+		LEFT JOIN team_members m ON m.team_id = t.team_id
+		LEFT JOIN users u ON u.user_id = m.user_id
+		ORDER BY t.team_name, m.joined_at, u.user_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query teams: %w", err)
+	}
+	defer rows.Close()
+
+	var teams []models.Team
+	for rows.Next() {
+		var teamID uuid.UUID
+		var name string
+		var parentID, userID uuid.NullUUID
+		var labels pq.StringArray
+		var archivedAt sql.NullTime
+		var username, role sql.NullString
+		var isActive sql.NullBool
+		if err := rows.Scan(&teamID, &name, &parentID, &labels, &archivedAt, &userID, &username, &isActive, &role); err != nil {
+			return nil, fmt.Errorf("scan team: %w", err)
+		}
+
+		if len(teams) == 0 || teams[len(teams)-1].ID != teamID.String() {
+			team := models.Team{
+				ID:               teamID.String(),
+				Name:             name,
+				LeadReviewLabels: []string(labels),
+			}
+			if parentID.Valid {
+				team.ParentID = parentID.UUID.String()
+			}
+			if archivedAt.Valid {
+				team.ArchivedAt = &archivedAt.Time
+			}
+			teams = append(teams, team)
+		}
+
+		if userID.Valid {
+			team := &teams[len(teams)-1]
+			team.Members = append(team.Members, models.User{
+				ID:       userID.UUID.String(),
+				Username: username.String,
+				TeamName: name,
+				IsActive: isActive.Bool,
+				Role:     models.TeamRole(role.String),
+			})
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+func (r *TeamsRepo) ImportTeams(ctx context.Context, teams []models.Team) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, team := range teams {
+		teamID, err := lockTeam(ctx, tx, team.Name)
+		if errors.Is(err, models.ErrTeamNotFound) {
+			err = tx.QueryRowContext(ctx, "INSERT INTO teams (team_name) VALUES ($1) RETURNING team_id", team.Name).Scan(&teamID)
+			if err != nil {
+				return fmt.Errorf("insert team: %w", err)
+			}
+		} else if err != nil {
+			return err
+		}
+
+		for _, u := range team.Members {
+			if err := upsertMember(ctx, tx, teamID, u); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SetRole changes the user's role in the team.
+func (r *TeamsRepo) SetRole(ctx context.Context, teamName string, userID uuid.UUID, role models.TeamRole) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE team_members m
+		SET role = $3
+		FROM teams t
+		WHERE t.team_id = m.team_id AND t.team_name = $1 AND m.user_id = $2
+	`, teamName, userID, role)
+	if err != nil {
+		return fmt.Errorf("set role: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrNotTeamMember
+	}
+
+	return nil
+}
+
+func (r *TeamsRepo) SetLeadReviewLabels(ctx context.Context, teamName string, labels []string) error {
+	if labels == nil {
+		labels = []string{}
+	}
+	res, err := r.db.ExecContext(ctx, "UPDATE teams SET lead_review_labels = $2 WHERE team_name = $1", teamName, stringArray(labels))
+	if err != nil {
+		return fmt.Errorf("set lead review labels: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrTeamNotFound
+	}
+
+	return nil
+}
+
+func (r *TeamsRepo) GetEscalationLeads(ctx context.Context, id uuid.UUID) ([]*models.User, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH RECURSIVE ancestors AS (
+			SELECT team_id, parent_team_id, 0 AS depth FROM teams WHERE team_id = $1
+			UNION ALL
+			SELECT t.team_id, t.parent_team_id, a.depth + 1
+			FROM teams t
+			JOIN ancestors a ON t.team_id = a.parent_team_id
+		)
+		SELECT u.user_id, u.username, t.team_name, u.is_active, m.role
+		FROM ancestors a
+		JOIN teams t ON t.team_id = a.team_id
+		JOIN team_members m ON m.team_id = a.team_id
+		JOIN users u ON u.user_id = m.user_id
+		WHERE m.role = 'lead'
+		  AND u.is_active = true
+		  AND t.archived_at IS NULL
+		ORDER BY a.depth, m.joined_at, u.user_id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("query leads: %w", err)
+	}
+	defer rows.Close()
+
+	seen := map[uuid.UUID]bool{}
+	var leads []*models.User
+	for rows.Next() {
+		u := &models.User{}
+		var userID uuid.UUID
+		if err := rows.Scan(&userID, &u.Username, &u.TeamName, &u.IsActive, &u.Role); err != nil {
+			return nil, fmt.Errorf("scan lead: %w", err)
+		}
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		u.ID = userID.String()
+		leads = append(leads, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return leads, nil
+}
+
+// RenameTeam changes the display name of the team.
+func (r *TeamsRepo) RenameTeam(ctx context.Context, id uuid.UUID, name string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1 AND team_id <> $2)", name, id).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("check team exists: %w", err)
+	}
+	if exists {
+		return models.ErrTeamExists
+	}
+
+	res, err := tx.ExecContext(ctx, "UPDATE teams SET team_name = $2 WHERE team_id = $1", id, name)
+	if err != nil {
+		return fmt.Errorf("rename team: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrTeamNotFound
+	}
+
+	return tx.Commit()
+}
+
+func (r *TeamsRepo) SetParent(ctx context.Context, id, parentID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var parent interface{}
+	if parentID != uuid.Nil {
+		var cycle bool
+		err = tx.QueryRowContext(ctx, `
+			WITH RECURSIVE ancestors AS (
+				SELECT team_id, parent_team_id FROM teams WHERE team_id = $2
+				UNION ALL
+				SELECT t.team_id, t.parent_team_id
+				FROM teams t
+				JOIN ancestors a ON t.team_id = a.parent_team_id
+			)
+			SELECT EXISTS(SELECT 1 FROM ancestors WHERE team_id = $1)
+		`, id, parentID).Scan(&cycle)
+		if err != nil {
+			return fmt.Errorf("check ancestors: %w", err)
+		}
+		if cycle {
+			return models.ErrTeamCycle
+		}
+		parent = parentID
+	}
+
+	res, err := tx.ExecContext(ctx, "UPDATE teams SET parent_team_id = $2 WHERE team_id = $1", id, parent)
+	if err != nil {
+		return fmt.Errorf("set parent: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrTeamNotFound
+	}
+
+	return tx.Commit()
+}
+
+func (r *TeamsRepo) GetExclusiveMembers(ctx context.Context, teamName string) ([]uuid.UUID, error) {
+	return exclusiveMembers(ctx, r.db, teamName, false)
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// exclusiveMembers returns the members of the team that belong to no other
+// active team, only the active ones when activeOnly is set.
+func exclusiveMembers(ctx context.Context, q queryer, teamName string, activeOnly bool) ([]uuid.UUID, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT m.user_id
+		FROM team_members m
+		JOIN teams t ON t.team_id = m.team_id
+		JOIN users u ON u.user_id = m.user_id
+		WHERE t.team_name = $1
+		  AND (u.is_active OR NOT $2)
+		  AND NOT EXISTS (
+			  SELECT 1
+			  FROM team_members o
+			  JOIN teams ot ON ot.team_id = o.team_id
+			  WHERE o.user_id = m.user_id
+				AND o.team_id <> m.team_id
+				AND ot.archived_at IS NULL
+		  )
+		ORDER BY m.user_id
+	`, teamName, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("query members: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan member: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// GetChildTeamIDs returns the direct sub-teams of the team ordered by name.
+func (r *TeamsRepo) GetChildTeamIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	return r.queryTeamIDs(ctx, `
+		SELECT team_id
+		FROM teams
+		WHERE parent_team_id = $1
+		ORDER BY team_name
+	`, id)
+}
+
+// GetSubtreeTeamIDs returns the team and every team below it.
+func (r *TeamsRepo) GetSubtreeTeamIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	return r.queryTeamIDs(ctx, `
+		WITH RECURSIVE subtree AS (
+			SELECT team_id FROM teams WHERE team_id = $1
+			UNION
+			SELECT t.team_id
+			FROM teams t
+			JOIN subtree s ON t.parent_team_id = s.team_id
+		)
+		SELECT team_id FROM subtree
+	`, id)
+}
+
+func (r *TeamsRepo) queryTeamIDs(ctx context.Context, query string, args ...interface{}) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query teams: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan team: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
 }
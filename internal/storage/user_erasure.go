@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"reviewer-service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func (r *UsersRepository) EraseUser(ctx context.Context, erasure *models.UserErasure, reassignments []models.ReviewerReassignment) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE users
+		SET username = $2, is_active = false, erased_at = now()
+		WHERE user_id = $1 AND erased_at IS NULL
+	`, erasure.UserID, erasure.Pseudonym)
+	if err != nil {
+		return fmt.Errorf("erase user: %w", err)
+	}
+	if err := requireAffected(res, models.ErrUserErased); err != nil {
+		return err
+	}
+
+	if err := applyReassignments(ctx, tx, reassignments); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM team_members WHERE user_id = $1`, erasure.UserID); err != nil {
+		return fmt.Errorf("remove memberships: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM bulk_operation_users WHERE user_id = $1`, erasure.UserID); err != nil {
+		return fmt.Errorf("remove from bulk operations: %w", err)
+	}
+
+	var approvedBy interface{}
+	if erasure.ApprovedBy != "" {
+		approvedBy = erasure.ApprovedBy
+	}
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO user_erasures (erasure_id, user_id, pseudonym, approved_by, reason, reassigned_prs, unresolved_prs)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`, erasure.ID, erasure.UserID, erasure.Pseudonym, approvedBy, erasure.Reason, erasure.ReassignedPRs, erasure.UnresolvedPRs).Scan(&erasure.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert erasure: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetUserErasures returns the erasure audit trail, newest first.
+func (r *UsersRepository) GetUserErasures(ctx context.Context, userID uuid.NullUUID) ([]models.UserErasure, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT erasure_id, user_id, pseudonym, approved_by, reason, reassigned_prs, unresolved_prs, created_at
+		FROM user_erasures
+		WHERE $1::uuid IS NULL OR user_id = $1
+		ORDER BY created_at DESC, erasure_id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	erasures := []models.UserErasure{}
+	for rows.Next() {
+		var e models.UserErasure
+		var id, erasedID uuid.UUID
+		var approvedBy uuid.NullUUID
+		err := rows.Scan(&id, &erasedID, &e.Pseudonym, &approvedBy, &e.Reason, &e.ReassignedPRs, &e.UnresolvedPRs, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		e.ID = id.String()
+		e.UserID = erasedID.String()
+		if approvedBy.Valid {
+			e.ApprovedBy = approvedBy.UUID.String()
+		}
+		erasures = append(erasures, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return erasures, nil
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"reviewer-service/internal/models"
 	"reviewer-service/internal/repository/users"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type UsersRepository struct {
@@ -26,24 +29,49 @@ func (r *UsersRepository) CreateUser(ctx context.Context, user models.User) erro
 		return err
 	}
 
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
 	const query = `
-		INSERT INTO users (user_id, username, team_name, is_active)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO users (user_id, username, is_active)
+		VALUES ($1, $2, $3)
 		ON CONFLICT (user_id) DO UPDATE SET 
 			username = EXCLUDED.username, 
-			team_name = EXCLUDED.team_name, 
 			is_active = EXCLUDED.is_active
+		WHERE users.erased_at IS NULL
 	`
 
-	_, err = r.db.ExecContext(ctx, query, userID, user.Username, user.TeamName, user.IsActive)
-	return err
+	if _, err := tx.ExecContext(ctx, query, userID, user.Username, user.IsActive); err != nil {
+		return err
+	}
+
+	if user.TeamName != "" {
+		var teamID uuid.UUID
+		err := tx.QueryRowContext(ctx, "SELECT team_id FROM teams WHERE team_name = $1", user.TeamName).Scan(&teamID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return models.ErrTeamNotFound
+			}
+			return err
+		}
+		if err := addMembership(ctx, tx, teamID, userID, user.Role); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 func (r *UsersRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	const query = `
-		SELECT user_id, username, team_name, is_active
-		FROM users
-		WHERE user_id = $1
+		SELECT u.user_id, u.username, COALESCE(t.team_name, ''), u.is_active
+		FROM users u
+		LEFT JOIN team_members m ON m.user_id = u.user_id AND m.is_primary
+		LEFT JOIN teams t ON t.team_id = m.team_id
+		WHERE u.user_id = $1
 	`
 
 	u := &models.User{}
@@ -62,11 +90,80 @@ func (r *UsersRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Us
 	return u, nil
 }
 
+func (r *UsersRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error) {
+	const query = `
+		SELECT u.user_id, u.username, COALESCE(t.team_name, ''), u.is_active
+		FROM users u
+		LEFT JOIN team_members m ON m.user_id = u.user_id AND m.is_primary
+		LEFT JOIN teams t ON t.team_id = m.team_id
+		WHERE u.user_id = ANY($1::uuid[])
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, uuidArray(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*models.User
+
+	for rows.Next() {
+		u := &models.User{}
+		var userID uuid.UUID
+		if err := rows.Scan(&userID, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+			return nil, err
+		}
+		u.ID = userID.String()
+		result = append(result, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetByUsernames returns the users with any of the given usernames.
+func (r *UsersRepository) GetByUsernames(ctx context.Context, usernames []string) ([]*models.User, error) {
+	const query = `
+		SELECT u.user_id, u.username, COALESCE(t.team_name, ''), u.is_active
+		FROM users u
+		LEFT JOIN team_members m ON m.user_id = u.user_id AND m.is_primary
+		LEFT JOIN teams t ON t.team_id = m.team_id
+		WHERE u.username = ANY($1::text[])
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, stringArray(usernames))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*models.User
+
+	for rows.Next() {
+		u := &models.User{}
+		var userID uuid.UUID
+		if err := rows.Scan(&userID, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+			return nil, err
+		}
+		u.ID = userID.String()
+		result = append(result, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 func (r *UsersRepository) SetIsActive(ctx context.Context, id uuid.UUID, isActive bool) error {
 	const query = `
 		UPDATE users
 		SET is_active = $1
-		WHERE user_id = $2
+		WHERE user_id = $2 AND erased_at IS NULL
 	`
 
 	res, err := r.db.ExecContext(ctx, query, isActive, id)
@@ -90,19 +187,113 @@ func (r *UsersRepository) SetTeamInactive(ctx context.Context, teamName string)
 	const query = `
 		UPDATE users
 		SET is_active = false
-		WHERE team_name = $1 AND is_active = true
+		WHERE user_id IN (
+			SELECT m.user_id
+			FROM team_members m
+			JOIN teams t ON t.team_id = m.team_id
+			WHERE t.team_name = $1
+		) AND is_active = true
 	`
 
 	_, err := r.db.ExecContext(ctx, query, teamName)
 	return err
 }
 
+// DeactivateUsers deactivates ids and applies the reassignments. When check is
+// set, it is called with the open PRs reviewed by ids, read after locking
+// their reviewer rows, and any error aborts the transaction.
+func (r *UsersRepository) DeactivateUsers(ctx context.Context, op models.BulkOperation, ids []uuid.UUID, reassignments []models.ReviewerReassignment, check func(openPRs []models.PullRequest) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if check != nil {
+		_, err = tx.ExecContext(ctx, `
+			SELECT 1
+			FROM pr_reviewers
+			WHERE pull_request_id IN (
+				SELECT pull_request_id FROM pr_reviewers WHERE reviewer_id = ANY($1::uuid[])
+			)
+			FOR UPDATE
+		`, uuidArray(ids))
+		if err != nil {
+			return fmt.Errorf("lock reviewers: %w", err)
+		}
+
+		openPRs, err := openPRsWithReviewers(ctx, tx, ids)
+		if err != nil {
+			return err
+		}
+		if err := check(openPRs); err != nil {
+			return err
+		}
+	}
+
+	if err := applyReassignments(ctx, tx, reassignments); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users
+		SET is_active = false
+		WHERE user_id = ANY($1::uuid[]) AND is_active = true
+	`, uuidArray(ids))
+	if err != nil {
+		return fmt.Errorf("deactivate users: %w", err)
+	}
+
+	op.Reassignments = reassignments
+	if err := insertBulkOperation(ctx, tx, op); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *UsersRepository) ReactivateUsers(ctx context.Context, operationID uuid.UUID, ids []uuid.UUID, restorations []models.ReviewerReassignment) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users
+		SET is_active = true
+		WHERE user_id = ANY($1::uuid[]) AND is_active = false AND erased_at IS NULL
+	`, uuidArray(ids))
+	if err != nil {
+		return fmt.Errorf("reactivate users: %w", err)
+	}
+
+	if err := applyReassignments(ctx, tx, restorations); err != nil {
+		return err
+	}
+
+	if operationID != uuid.Nil {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE bulk_operations
+			SET reactivated_at = now()
+			WHERE operation_id = $1
+		`, operationID)
+		if err != nil {
+			return fmt.Errorf("mark operation reactivated: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
 func (r *UsersRepository) GetActiveByTeam(ctx context.Context, teamName string) ([]*models.User, error) {
 	const query = `
-		SELECT user_id, username, team_name, is_active
-		FROM users
-		WHERE team_name = $1
-		  AND is_active = true
+		SELECT u.user_id, u.username, t.team_name, u.is_active
+		FROM users u
+		JOIN team_members m ON m.user_id = u.user_id
+		JOIN teams t ON t.team_id = m.team_id
+		WHERE t.team_name = $1
+		  AND u.is_active = true
 	`
 
 	rows, err := r.db.QueryContext(ctx, query, teamName)
@@ -130,6 +321,156 @@ func (r *UsersRepository) GetActiveByTeam(ctx context.Context, teamName string)
 	return result, nil
 }
 
+func (r *UsersRepository) GetAllActive(ctx context.Context) ([]*models.User, error) {
+	const query = `
+		SELECT u.user_id, u.username, COALESCE(p.team_name, ''), u.is_active
+		FROM users u
+		LEFT JOIN team_members pm ON pm.user_id = u.user_id AND pm.is_primary
+		LEFT JOIN teams p ON p.team_id = pm.team_id
+		WHERE u.is_active = true
+		  AND EXISTS (
+			SELECT 1
+			FROM team_members m
+			JOIN teams t ON t.team_id = m.team_id
+			WHERE m.user_id = u.user_id
+			  AND m.role <> 'observer'
+			  AND t.archived_at IS NULL
+		  )
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*models.User
+
+	for rows.Next() {
+		u := &models.User{}
+		var userID uuid.UUID
+		if err := rows.Scan(&userID, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+			return nil, err
+		}
+		u.ID = userID.String()
+		result = append(result, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *UsersRepository) GetActiveInTeams(ctx context.Context, teamIDs []uuid.UUID) ([]*models.User, error) {
+	const query = `
+		SELECT u.user_id, u.username, COALESCE(p.team_name, ''), u.is_active
+		FROM users u
+		LEFT JOIN team_members pm ON pm.user_id = u.user_id AND pm.is_primary
+		LEFT JOIN teams p ON p.team_id = pm.team_id
+		WHERE u.is_active = true
+		  AND EXISTS (
+			SELECT 1
+			FROM team_members m
+			JOIN teams t ON t.team_id = m.team_id
+			WHERE m.user_id = u.user_id
+			  AND m.role <> 'observer'
+			  AND t.archived_at IS NULL
+			  AND t.team_id = ANY($1::uuid[])
+		  )
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, uuidArray(teamIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*models.User
+
+	for rows.Next() {
+		u := &models.User{}
+		var userID uuid.UUID
+		if err := rows.Scan(&userID, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+			return nil, err
+		}
+		u.ID = userID.String()
+		result = append(result, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *UsersRepository) GetTeamNames(ctx context.Context, id uuid.UUID) ([]string, error) {
+	const query = `
+		SELECT t.team_name
+		FROM team_members m
+		JOIN teams t ON t.team_id = m.team_id
+		WHERE m.user_id = $1
+		ORDER BY m.is_primary DESC, t.team_name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// SetPrimaryTeam marks one of the user's teams as primary.
+func (r *UsersRepository) SetPrimaryTeam(ctx context.Context, id uuid.UUID, teamName string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var teamID uuid.UUID
+	err = tx.QueryRowContext(ctx, `
+		SELECT m.team_id
+		FROM team_members m
+		JOIN teams t ON t.team_id = m.team_id
+		WHERE m.user_id = $1 AND t.team_name = $2
+	`, id, teamName).Scan(&teamID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotTeamMember
+		}
+		return fmt.Errorf("query membership: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE team_members SET is_primary = false WHERE user_id = $1 AND is_primary", id)
+	if err != nil {
+		return fmt.Errorf("clear primary team: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE team_members SET is_primary = true WHERE user_id = $1 AND team_id = $2", id, teamID)
+	if err != nil {
+		return fmt.Errorf("set primary team: %w", err)
+	}
+
+	return tx.Commit()
+}
+
 func (r *UsersRepository) GetAssignedPullRequests(ctx context.Context, userID uuid.UUID) ([]*models.PullRequest, error) {
 	const query = `
 		SELECT pr.pull_request_id,
@@ -183,3 +524,186 @@ func (r *UsersRepository) GetAssignedPullRequests(ctx context.Context, userID uu
 
 	return prs, nil
 }
+
+func (r *UsersRepository) GetAuthoredPullRequests(ctx context.Context, authorID uuid.UUID, status models.PullRequestStatus) ([]models.AuthoredPullRequest, error) {
+	const query = `
+		SELECT pr.pull_request_id,
+		       pr.pull_request_name,
+		       pr.author_id,
+		       pr.status,
+		       pr.created_at,
+		       pr.merged_at,
+		       pr.labels,
+		       rev.reviewer_id,
+		       rev.verdict,
+		       rev.assigned_at,
+		       rev.decided_at
+		FROM pull_requests pr
+		LEFT JOIN pr_reviewers rev ON rev.pull_request_id = pr.pull_request_id
+		WHERE pr.author_id = $1 AND ($2 = '' OR pr.status = $2)
+		ORDER BY pr.created_at DESC, pr.pull_request_id, rev.order_index
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, authorID, string(status))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prs := []models.AuthoredPullRequest{}
+	for rows.Next() {
+		var pr models.AuthoredPullRequest
+		var labels pq.StringArray
+		var reviewerID uuid.NullUUID
+		var verdict sql.NullString
+		var assignedAt, decidedAt sql.NullTime
+		err := rows.Scan(
+			&pr.ID,
+			&pr.Name,
+			&pr.AuthorID,
+			&pr.Status,
+			&pr.CreatedAt,
+			&pr.MergedAt,
+			&labels,
+			&reviewerID,
+			&verdict,
+			&assignedAt,
+			&decidedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if n := len(prs); n == 0 || prs[n-1].ID != pr.ID {
+			pr.Labels = []string(labels)
+			prs = append(prs, pr)
+		}
+		if !reviewerID.Valid {
+			continue
+		}
+
+		review := models.Review{
+			ReviewerID: reviewerID.UUID.String(),
+			Verdict:    models.ReviewVerdict(verdict.String),
+			AssignedAt: assignedAt.Time,
+		}
+		if decidedAt.Valid {
+			review.DecidedAt = &decidedAt.Time
+		}
+		last := &prs[len(prs)-1]
+		last.Reviews = append(last.Reviews, review)
+		last.Reviewers = append(last.Reviewers, review.ReviewerID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return prs, nil
+}
+
+const profileQuery = `
+		SELECT u.user_id, u.username, COALESCE(t.team_name, ''), u.is_active,
+		       ARRAY(
+		           SELECT mt.team_name
+		           FROM team_members mm
+		           JOIN teams mt ON mt.team_id = mm.team_id
+		           WHERE mm.user_id = u.user_id
+		           ORDER BY mm.is_primary DESC, mm.joined_at, mt.team_name
+		       ),
+		       (
+		           SELECT COUNT(*)
+		           FROM pr_reviewers rev
+		           JOIN pull_requests pr ON pr.pull_request_id = rev.pull_request_id
+		           WHERE rev.reviewer_id = u.user_id AND pr.status = 'OPEN'
+		       ),
+		       ARRAY(
+		           SELECT pr.pull_request_id
+		           FROM pull_requests pr
+		           WHERE pr.author_id = u.user_id AND pr.status = 'OPEN'
+		           ORDER BY pr.created_at, pr.pull_request_id
+		       )
+		FROM users u
+		LEFT JOIN team_members m ON m.user_id = u.user_id AND m.is_primary
+		LEFT JOIN teams t ON t.team_id = m.team_id
+	`
+
+const userFilterClause = `
+		WHERE ($1 = '' OR EXISTS (
+		          SELECT 1
+		          FROM team_members fm
+		          JOIN teams ft ON ft.team_id = fm.team_id
+		          WHERE fm.user_id = u.user_id AND ft.team_name = $1 AND ($4 = '' OR fm.role = $4)
+		      ))
+		  AND ($1 <> '' OR $4 = '' OR EXISTS (
+		          SELECT 1 FROM team_members fm WHERE fm.user_id = u.user_id AND fm.role = $4
+		      ))
+		  AND ($2::boolean IS NULL OR u.is_active = $2)
+		  AND ($3 = '' OR lower(u.username) LIKE lower($3) || '%' ESCAPE '\')
+	`
+
+func userFilterArgs(filter models.UserFilter) []interface{} {
+	var isActive interface{}
+	if filter.IsActive != nil {
+		isActive = *filter.IsActive
+	}
+	prefix := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(filter.NamePrefix)
+	return []interface{}{filter.TeamName, isActive, prefix, string(filter.Role)}
+}
+
+func (r *UsersRepository) GetProfile(ctx context.Context, id uuid.UUID) (*models.UserProfile, error) {
+	profiles, err := r.queryProfiles(ctx, profileQuery+"WHERE u.user_id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(profiles) == 0 {
+		return nil, nil
+	}
+	return profiles[0], nil
+}
+
+func (r *UsersRepository) ListUsers(ctx context.Context, filter models.UserFilter) ([]*models.UserProfile, error) {
+	args := append(userFilterArgs(filter), filter.Limit, filter.Offset)
+	return r.queryProfiles(ctx, profileQuery+userFilterClause+`
+		ORDER BY u.username, u.user_id
+		LIMIT $5 OFFSET $6
+	`, args...)
+}
+
+// CountUsers returns how many users match the filter, ignoring its page.
+func (r *UsersRepository) CountUsers(ctx context.Context, filter models.UserFilter) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users u"+userFilterClause, userFilterArgs(filter)...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count users: %w", err)
+	}
+	return count, nil
+}
+
+func (r *UsersRepository) queryProfiles(ctx context.Context, query string, args ...interface{}) ([]*models.UserProfile, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query users: %w", err)
+	}
+	defer rows.Close()
+
+	var profiles []*models.UserProfile
+	for rows.Next() {
+		p := &models.UserProfile{}
+		var userID uuid.UUID
+		var teams, authored pq.StringArray
+		if err := rows.Scan(&userID, &p.Username, &p.TeamName, &p.IsActive, &teams, &p.OpenReviews, &authored); err != nil {
+			return nil, fmt.Errorf("scan user: %w", err)
+		}
+		p.ID = userID.String()
+		p.Teams = []string(teams)
+		p.AuthoredOpenPRs = []string(authored)
+		profiles = append(profiles, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
@@ -3,12 +3,18 @@ package tests
 import (
 	"bytes"
 	"database/sql"
+	"encoding/csv"
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"reviewer-service/cmd/inits"
+	"reviewer-service/internal/handlers"
 	"reviewer-service/internal/models"
+	"strings"
 	"testing"
+	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
@@ -37,13 +43,71 @@ func setupTestDB(t *testing.T) *sql.DB {
 	return db
 }
 
+// testServer serves the routes of a service on a freshly migrated test database.
+type testServer struct {
+	t       *testing.T
+	handler http.Handler
+}
+
+func newTestServer(t *testing.T) *testServer {
+	db := setupTestDB(t)
+	t.Cleanup(func() { db.Close() })
+
+	require.NoError(t, inits.RunMigrations(db))
+
+	services, err := inits.InitServices(db)
+	require.NoError(t, err)
+
+	return &testServer{t: t, handler: inits.SetupRoutes(services)}
+}
+
+func (s *testServer) post(path string, body interface{}) *httptest.ResponseRecorder {
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	s.handler.ServeHTTP(w, req)
+	return w
+}
+
+func (s *testServer) get(path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	return w
+}
+
+func (s *testServer) addTeam(name string, members []handlers.UserInput) {
+	w := s.post("/team/add", handlers.TeamRequest{TeamName: name, Members: members})
+	require.Equal(s.t, http.StatusCreated, w.Code, w.Body.String())
+}
+
+func (s *testServer) createPR(id, name, authorID string, labels ...string) models.PullRequest {
+	body := map[string]interface{}{
+		"pull_request_id":   id,
+		"pull_request_name": name,
+		"author_id":         authorID,
+	}
+	if len(labels) > 0 {
+		body["labels"] = labels
+	}
+	w := s.post("/pullRequest/create", body)
+	require.Equal(s.t, http.StatusCreated, w.Code, w.Body.String())
+
+	var resp struct {
+		PR models.PullRequest `json:"pr"`
+	}
+	require.NoError(s.t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.PR
+}
+
 func TestCreateTeamAndPR(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
 
 	require.NoError(t, inits.RunMigrations(db))
 
-	services := inits.InitServices(db)
+	services, err := inits.InitServices(db)
+	require.NoError(t, err)
 	handler := inits.SetupRoutes(services)
 
 	teamReq := map[string]interface{}{
@@ -91,7 +155,8 @@ func TestMergePR(t *testing.T) {
 
 	require.NoError(t, inits.RunMigrations(db))
 
-	services := inits.InitServices(db)
+	services, err := inits.InitServices(db)
+	require.NoError(t, err)
 	handler := inits.SetupRoutes(services)
 
 	teamReq := map[string]interface{}{
@@ -148,7 +213,8 @@ func TestReassignAfterMerge(t *testing.T) {
 
 	require.NoError(t, inits.RunMigrations(db))
 
-	services := inits.InitServices(db)
+	services, err := inits.InitServices(db)
+	require.NoError(t, err)
 	handler := inits.SetupRoutes(services)
 
 	teamReq := map[string]interface{}{
@@ -206,7 +272,8 @@ func TestStatistics(t *testing.T) {
 
 	require.NoError(t, inits.RunMigrations(db))
 
-	services := inits.InitServices(db)
+	services, err := inits.InitServices(db)
+	require.NoError(t, err)
 	handler := inits.SetupRoutes(services)
 
 	teamReq := map[string]interface{}{
@@ -239,14 +306,1787 @@ func TestStatistics(t *testing.T) {
 
 	var statsResp struct {
 		UserAssignments []struct {
-			UserID string `json:"UserID"`
-			Count  int    `json:"Count"`
+			UserID string `json:"user_id"`
+			Count  int    `json:"assignments"`
 		} `json:"user_assignments"`
 		PRStats struct {
-			TotalPRs int `json:"TotalPRs"`
+			TotalPRs int `json:"total_prs"`
 		} `json:"pr_stats"`
 	}
 	json.Unmarshal(w.Body.Bytes(), &statsResp)
 
 	assert.Greater(t, statsResp.PRStats.TotalPRs, 0)
+
+	req = httptest.NewRequest("GET", "/statistics?from=2000-01-01&to=2000-03-31", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var windowResp struct {
+		From            time.Time `json:"from"`
+		To              time.Time `json:"to"`
+		UserAssignments []struct {
+			Count int `json:"assignments"`
+		} `json:"user_assignments"`
+		PRStats struct {
+			TotalPRs         int `json:"total_prs"`
+			TotalAssignments int `json:"total_assignments"`
+		} `json:"pr_stats"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &windowResp)
+	assert.Equal(t, time.Date(2000, 4, 1, 0, 0, 0, 0, time.UTC), windowResp.To)
+	assert.Equal(t, 0, windowResp.PRStats.TotalPRs)
+	assert.Equal(t, 0, windowResp.PRStats.TotalAssignments)
+	for _, stat := range windowResp.UserAssignments {
+		assert.Equal(t, 0, stat.Count)
+	}
+
+	for _, query := range []string{"from=yesterday", "from=2000-02-01&to=2000-01-01"} {
+		req = httptest.NewRequest("GET", "/statistics?"+query, nil)
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	req = httptest.NewRequest("GET", "/statistics?team_name=stats-team", nil)
+	req.Header.Set("Accept", "text/csv")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"metric", "user_id", "username", "value"}, records[0])
+	assert.Equal(t, []string{"total_prs", "", "", "1"}, records[1])
+	assert.Equal(t, []string{"assignments", "ffffffff-ffff-ffff-ffff-ffffffffffff", "StatsUser2", "1"}, records[5])
+
+	req = httptest.NewRequest("GET", "/metrics", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, w.Body.String(), "# TYPE reviewer_service_pull_requests_created gauge")
+	assert.Contains(t, w.Body.String(), `reviewer_service_team_assignments{team="stats-team"} 1`)
+	assert.NotContains(t, w.Body.String(), "StatsUser2")
+}
+
+func TestBulkDeactivateUsers(t *testing.T) {
+	srv := newTestServer(t)
+
+	srv.addTeam("bulk-team", []handlers.UserInput{
+		{UserID: "a1a1a1a1-0000-0000-0000-000000000001", Username: "BulkAuthor", IsActive: true},
+		{UserID: "a1a1a1a1-0000-0000-0000-000000000002", Username: "BulkUser2", IsActive: true},
+		{UserID: "a1a1a1a1-0000-0000-0000-000000000003", Username: "BulkUser3", IsActive: true},
+		{UserID: "a1a1a1a1-0000-0000-0000-000000000004", Username: "BulkUser4", IsActive: true},
+	})
+
+	pr := srv.createPR("pr-bulk-1", "Bulk PR", "a1a1a1a1-0000-0000-0000-000000000001")
+	require.Len(t, pr.Reviewers, 2)
+	deactivated := pr.Reviewers[0]
+
+	w := srv.post("/users/bulkDeactivate", map[string]interface{}{
+		"user_ids": []string{deactivated},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var bulkResp struct {
+		DeactivatedUsers []string `json:"deactivated_users"`
+		ReassignedPRs    []struct {
+			PRID         string   `json:"pr_id"`
+			Replaced     []string `json:"replaced"`
+			NewReviewers []string `json:"new_reviewers"`
+		} `json:"reassigned_prs"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &bulkResp)
+	assert.Equal(t, []string{deactivated}, bulkResp.DeactivatedUsers)
+	require.Len(t, bulkResp.ReassignedPRs, 1)
+	assert.Equal(t, "pr-bulk-1", bulkResp.ReassignedPRs[0].PRID)
+	assert.NotContains(t, pr.Reviewers, bulkResp.ReassignedPRs[0].NewReviewers[0])
+	assert.NotEqual(t, "a1a1a1a1-0000-0000-0000-000000000001", bulkResp.ReassignedPRs[0].NewReviewers[0])
+
+	w = srv.post("/users/bulkDeactivate", map[string]interface{}{
+		"user_ids": []string{"a1a1a1a1-0000-0000-0000-0000000000ff"},
+	})
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestBulkDeactivateTeamDryRun(t *testing.T) {
+	srv := newTestServer(t)
+
+	srv.addTeam("dry-run-team", []handlers.UserInput{
+		{UserID: "b2b2b2b2-0000-0000-0000-000000000001", Username: "DryAuthor", IsActive: true},
+		{UserID: "b2b2b2b2-0000-0000-0000-000000000002", Username: "DryReviewer", IsActive: true},
+	})
+
+	srv.createPR("pr-dry-1", "Dry Run PR", "b2b2b2b2-0000-0000-0000-000000000001")
+
+	type bulkResponse struct {
+		DeactivatedUsers []string `json:"deactivated_users"`
+		UnresolvedPRs    []struct {
+			PRID      string   `json:"pr_id"`
+			Reviewers []string `json:"reviewers"`
+			Reason    string   `json:"reason"`
+		} `json:"unresolved_prs"`
+		DryRun    bool   `json:"dry_run"`
+		PlanToken string `json:"plan_token"`
+	}
+
+	w := srv.post("/users/bulkDeactivateTeam", map[string]interface{}{"team_name": "dry-run-team", "dry_run": true})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var plan bulkResponse
+	json.Unmarshal(w.Body.Bytes(), &plan)
+	assert.True(t, plan.DryRun)
+	assert.Len(t, plan.DeactivatedUsers, 2)
+	assert.NotEmpty(t, plan.PlanToken)
+	require.Len(t, plan.UnresolvedPRs, 1)
+	assert.Equal(t, "pr-dry-1", plan.UnresolvedPRs[0].PRID)
+	assert.Equal(t, "NO_CANDIDATE", plan.UnresolvedPRs[0].Reason)
+
+	w = srv.get("/team/get?team_name=dry-run-team")
+
+	var team models.Team
+	json.Unmarshal(w.Body.Bytes(), &team)
+	for _, member := range team.Members {
+		assert.True(t, member.IsActive)
+	}
+
+	w = srv.post("/users/bulkDeactivateTeam", map[string]interface{}{
+		"team_name":      "dry-run-team",
+		"plan_token":     plan.PlanToken,
+		"unresolved_prs": plan.UnresolvedPRs,
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var applied bulkResponse
+	json.Unmarshal(w.Body.Bytes(), &applied)
+	assert.False(t, applied.DryRun)
+	assert.ElementsMatch(t, plan.DeactivatedUsers, applied.DeactivatedUsers)
+
+	w = srv.post("/users/bulkDeactivateTeam", map[string]interface{}{
+		"team_name":      "dry-run-team",
+		"plan_token":     plan.PlanToken,
+		"unresolved_prs": plan.UnresolvedPRs,
+	})
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestBulkDeactivateTeamRejectsEditedPlan(t *testing.T) {
+	srv := newTestServer(t)
+
+	const (
+		author   = "b3b3b3b3-0000-0000-0000-000000000001"
+		outsider = "b3b3b3b3-0000-0000-0000-000000000004"
+	)
+	srv.addTeam("tamper-team", []handlers.UserInput{
+		{UserID: author, Username: "TamperAuthor", IsActive: true},
+		{UserID: "b3b3b3b3-0000-0000-0000-000000000002", Username: "TamperReviewer1", IsActive: true},
+		{UserID: "b3b3b3b3-0000-0000-0000-000000000003", Username: "TamperReviewer2", IsActive: true},
+	})
+	srv.addTeam("tamper-other", []handlers.UserInput{
+		{UserID: outsider, Username: "TamperOutsider", IsActive: true},
+	})
+	srv.createPR("pr-tamper-1", "Tamper PR", author)
+
+	w := srv.post("/users/bulkDeactivateTeam", map[string]interface{}{
+		"team_name": "tamper-team",
+		"fallback":  "cross_team",
+		"dry_run":   true,
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	var plan struct {
+		ReassignedPRs []struct {
+			PRID         string   `json:"pr_id"`
+			Replaced     []string `json:"replaced"`
+			NewReviewers []string `json:"new_reviewers"`
+			Fallback     string   `json:"fallback"`
+		} `json:"reassigned_prs"`
+		UnresolvedPRs []map[string]interface{} `json:"unresolved_prs"`
+		PlanToken     string                   `json:"plan_token"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &plan)
+	require.Len(t, plan.ReassignedPRs, 1)
+	require.NotEmpty(t, plan.ReassignedPRs[0].NewReviewers)
+
+	edited := plan.ReassignedPRs[0]
+	edited.NewReviewers = append([]string(nil), edited.NewReviewers...)
+	if edited.NewReviewers[0] == outsider {
+		edited.NewReviewers[0] = "b3b3b3b3-0000-0000-0000-000000000005"
+	} else {
+		edited.NewReviewers[0] = outsider
+	}
+	w = srv.post("/users/bulkDeactivateTeam", map[string]interface{}{
+		"team_name":      "tamper-team",
+		"plan_token":     plan.PlanToken,
+		"reassigned_prs": []interface{}{edited},
+		"unresolved_prs": plan.UnresolvedPRs,
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = srv.post("/users/bulkDeactivateTeam", map[string]interface{}{
+		"team_name":      "tamper-team",
+		"plan_token":     plan.PlanToken,
+		"reassigned_prs": plan.ReassignedPRs,
+		"unresolved_prs": plan.UnresolvedPRs,
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestBulkDeactivateFallbackRemove(t *testing.T) {
+	srv := newTestServer(t)
+
+	srv.addTeam("fallback-team", []handlers.UserInput{
+		{UserID: "c3c3c3c3-0000-0000-0000-000000000001", Username: "FallbackAuthor", IsActive: true},
+		{UserID: "c3c3c3c3-0000-0000-0000-000000000002", Username: "FallbackReviewer", IsActive: true},
+	})
+
+	srv.createPR("pr-fallback-1", "Fallback PR", "c3c3c3c3-0000-0000-0000-000000000001")
+
+	w := srv.post("/users/bulkDeactivate", map[string]interface{}{
+		"user_ids": []string{"c3c3c3c3-0000-0000-0000-000000000002"},
+		"fallback": "remove",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var bulkResp struct {
+		ReassignedPRs []struct {
+			PRID     string   `json:"pr_id"`
+			Removed  []string `json:"removed"`
+			Fallback string   `json:"fallback"`
+		} `json:"reassigned_prs"`
+		UnresolvedPRs []struct {
+			PRID string `json:"pr_id"`
+		} `json:"unresolved_prs"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &bulkResp)
+	require.Len(t, bulkResp.ReassignedPRs, 1)
+	assert.Equal(t, []string{"c3c3c3c3-0000-0000-0000-000000000002"}, bulkResp.ReassignedPRs[0].Removed)
+	assert.Equal(t, "remove", bulkResp.ReassignedPRs[0].Fallback)
+	assert.Empty(t, bulkResp.UnresolvedPRs)
+
+	w = srv.get("/users/getReview?user_id=c3c3c3c3-0000-0000-0000-000000000002")
+
+	var reviewResp struct {
+		PullRequests []models.PullRequest `json:"pull_requests"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &reviewResp)
+	assert.Empty(t, reviewResp.PullRequests)
+}
+
+func TestAsyncBulkDeactivateJob(t *testing.T) {
+	srv := newTestServer(t)
+
+	srv.addTeam("async-team", []handlers.UserInput{
+		{UserID: "d4d4d4d4-0000-0000-0000-000000000001", Username: "AsyncUser1", IsActive: true},
+		{UserID: "d4d4d4d4-0000-0000-0000-000000000002", Username: "AsyncUser2", IsActive: true},
+	})
+
+	w := srv.post("/users/bulkDeactivateTeam", map[string]interface{}{"team_name": "async-team", "async": true})
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	type jobResponse struct {
+		Job struct {
+			JobID  string `json:"job_id"`
+			Status string `json:"status"`
+			Result struct {
+				DeactivatedUsers []string `json:"deactivated_users"`
+			} `json:"result"`
+		} `json:"job"`
+	}
+
+	var submitted jobResponse
+	json.Unmarshal(w.Body.Bytes(), &submitted)
+	require.NotEmpty(t, submitted.Job.JobID)
+
+	var job jobResponse
+	require.Eventually(t, func() bool {
+		w := srv.get("/jobs/get?job_id=" + submitted.Job.JobID)
+		if w.Code != http.StatusOK {
+			return false
+		}
+		json.Unmarshal(w.Body.Bytes(), &job)
+		return job.Job.Status != "PENDING" && job.Job.Status != "RUNNING"
+	}, 5*time.Second, 50*time.Millisecond)
+
+	assert.Equal(t, "SUCCEEDED", job.Job.Status)
+	assert.Len(t, job.Job.Result.DeactivatedUsers, 2)
+
+	w = srv.post("/jobs/cancel", map[string]interface{}{"job_id": submitted.Job.JobID})
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestBulkReactivateRestoresReviews(t *testing.T) {
+	srv := newTestServer(t)
+
+	srv.addTeam("reactivate-team", []handlers.UserInput{
+		{UserID: "e5e5e5e5-0000-0000-0000-000000000001", Username: "ReactAuthor", IsActive: true},
+		{UserID: "e5e5e5e5-0000-0000-0000-000000000002", Username: "ReactUser2", IsActive: true},
+		{UserID: "e5e5e5e5-0000-0000-0000-000000000003", Username: "ReactUser3", IsActive: true},
+		{UserID: "e5e5e5e5-0000-0000-0000-000000000004", Username: "ReactUser4", IsActive: true},
+	})
+
+	pr := srv.createPR("pr-reactivate-1", "Reactivate PR", "e5e5e5e5-0000-0000-0000-000000000001")
+	require.Len(t, pr.Reviewers, 2)
+	original := pr.Reviewers[0]
+
+	w := srv.post("/users/bulkDeactivate", map[string]interface{}{"user_ids": []string{original}})
+
+	var bulkResp struct {
+		OperationID string `json:"operation_id"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &bulkResp)
+	require.NotEmpty(t, bulkResp.OperationID)
+
+	w = srv.post("/users/bulkReactivate", map[string]interface{}{
+		"operation_id":    bulkResp.OperationID,
+		"restore_reviews": true,
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var reactivateResp struct {
+		ReactivatedUsers []string `json:"reactivated_users"`
+		RestoredPRs      []struct {
+			PRID         string   `json:"pr_id"`
+			NewReviewers []string `json:"new_reviewers"`
+		} `json:"restored_prs"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &reactivateResp)
+	assert.Equal(t, []string{original}, reactivateResp.ReactivatedUsers)
+	require.Len(t, reactivateResp.RestoredPRs, 1)
+	assert.Equal(t, []string{original}, reactivateResp.RestoredPRs[0].NewReviewers)
+
+	w = srv.get("/users/getReview?user_id=" + original)
+
+	var reviewResp struct {
+		PullRequests []models.PullRequest `json:"pull_requests"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &reviewResp)
+	require.Len(t, reviewResp.PullRequests, 1)
+	assert.Equal(t, "pr-reactivate-1", reviewResp.PullRequests[0].ID)
+}
+
+func TestBulkReactivateRestoresRemovedReviewerBeyondTwoSlots(t *testing.T) {
+	srv := newTestServer(t)
+
+	const author = "e6e6e6e6-0000-0000-0000-000000000001"
+	srv.addTeam("reactivate-wide-team", []handlers.UserInput{
+		{UserID: author, Username: "ReactWideAuthor", IsActive: true},
+		{UserID: "e6e6e6e6-0000-0000-0000-000000000002", Username: "ReactWide2", IsActive: true},
+		{UserID: "e6e6e6e6-0000-0000-0000-000000000003", Username: "ReactWide3", IsActive: true},
+		{UserID: "e6e6e6e6-0000-0000-0000-000000000004", Username: "ReactWide4", IsActive: true},
+	})
+	w := srv.post("/team/settings/update", map[string]interface{}{
+		"team_name": "reactivate-wide-team",
+		"settings":  map[string]interface{}{"reviewer_count": 3},
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	pr := srv.createPR("pr-reactivate-wide", "Reactivate wide PR", author)
+	require.Len(t, pr.Reviewers, 3)
+	removed := pr.Reviewers[0]
+
+	w = srv.post("/users/bulkDeactivate", map[string]interface{}{"user_ids": []string{removed}, "fallback": "remove"})
+	require.Equal(t, http.StatusOK, w.Code)
+	var bulkResp struct {
+		OperationID string `json:"operation_id"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &bulkResp)
+	require.NotEmpty(t, bulkResp.OperationID)
+
+	w = srv.post("/users/bulkReactivate", map[string]interface{}{
+		"operation_id":    bulkResp.OperationID,
+		"restore_reviews": true,
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	var reactivateResp struct {
+		RestoredPRs []struct {
+			PRID         string   `json:"pr_id"`
+			NewReviewers []string `json:"new_reviewers"`
+		} `json:"restored_prs"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &reactivateResp)
+	require.Len(t, reactivateResp.RestoredPRs, 1)
+	assert.Equal(t, []string{removed}, reactivateResp.RestoredPRs[0].NewReviewers)
+}
+
+func TestTeamMembershipChanges(t *testing.T) {
+	srv := newTestServer(t)
+
+	const (
+		author   = "e5e5e5e5-0000-0000-0000-000000000001"
+		reviewer = "e5e5e5e5-0000-0000-0000-000000000002"
+		newcomer = "e5e5e5e5-0000-0000-0000-000000000003"
+		other    = "e5e5e5e5-0000-0000-0000-000000000004"
+	)
+
+	srv.addTeam("membership-src", []handlers.UserInput{
+		{UserID: author, Username: "MemberAuthor", IsActive: true},
+		{UserID: reviewer, Username: "MemberReviewer", IsActive: true},
+	})
+	srv.addTeam("membership-dst", []handlers.UserInput{
+		{UserID: other, Username: "MemberOther", IsActive: true},
+	})
+	srv.createPR("pr-membership-1", "Membership PR", author)
+
+	w := srv.post("/team/addMembers", map[string]interface{}{
+		"team_name": "membership-src",
+		"members": []map[string]interface{}{
+			{"user_id": newcomer, "username": "MemberNewcomer", "is_active": true},
+		},
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = srv.post("/team/moveMember", map[string]interface{}{
+		"user_id":   reviewer,
+		"team_name": "membership-dst",
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var moveResp struct {
+		FromTeam      string `json:"from_team"`
+		ToTeam        string `json:"to_team"`
+		ReassignedPRs []struct {
+			PRID         string   `json:"pr_id"`
+			Replaced     []string `json:"replaced"`
+			NewReviewers []string `json:"new_reviewers"`
+		} `json:"reassigned_prs"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &moveResp)
+	assert.Equal(t, "membership-src", moveResp.FromTeam)
+	assert.Equal(t, "membership-dst", moveResp.ToTeam)
+	require.Len(t, moveResp.ReassignedPRs, 1)
+	assert.Equal(t, []string{reviewer}, moveResp.ReassignedPRs[0].Replaced)
+	assert.Equal(t, []string{newcomer}, moveResp.ReassignedPRs[0].NewReviewers)
+
+	w = srv.get("/team/get?team_name=membership-dst")
+
+	var team models.Team
+	json.Unmarshal(w.Body.Bytes(), &team)
+	assert.Len(t, team.Members, 2)
+
+	w = srv.post("/team/removeMember", map[string]interface{}{
+		"team_name": "membership-src",
+		"user_id":   newcomer,
+		"fallback":  "remove",
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = srv.get("/users/getReview?user_id=" + newcomer)
+
+	var reviewResp struct {
+		PullRequests []models.PullRequest `json:"pull_requests"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &reviewResp)
+	assert.Empty(t, reviewResp.PullRequests)
+
+	w = srv.get("/team/get?team_name=membership-src")
+
+	team = models.Team{}
+	json.Unmarshal(w.Body.Bytes(), &team)
+	assert.Len(t, team.Members, 1)
+}
+
+func TestMultiTeamMembership(t *testing.T) {
+	srv := newTestServer(t)
+
+	const (
+		squadAuthor = "a7a7a7a7-0000-0000-0000-000000000001"
+		guildAuthor = "a7a7a7a7-0000-0000-0000-000000000002"
+		shared      = "a7a7a7a7-0000-0000-0000-000000000003"
+	)
+
+	srv.addTeam("multi-squad", []handlers.UserInput{
+		{UserID: squadAuthor, Username: "SquadAuthor", IsActive: true},
+		{UserID: shared, Username: "Shared", IsActive: true},
+	})
+	srv.addTeam("multi-guild", []handlers.UserInput{
+		{UserID: guildAuthor, Username: "GuildAuthor", IsActive: true},
+	})
+
+	w := srv.post("/team/addMembers", map[string]interface{}{
+		"team_name": "multi-guild",
+		"members": []map[string]interface{}{
+			{"user_id": shared, "username": "Shared", "is_active": true},
+		},
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	for _, tc := range []struct{ id, author string }{
+		{"pr-multi-squad", squadAuthor},
+		{"pr-multi-guild", guildAuthor},
+	} {
+		pr := srv.createPR(tc.id, tc.id, tc.author)
+		assert.Equal(t, []string{shared}, pr.Reviewers)
+	}
+
+	w = srv.post("/users/setPrimaryTeam", map[string]interface{}{
+		"user_id":   shared,
+		"team_name": "multi-guild",
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var primaryResp struct {
+		User  models.User `json:"user"`
+		Teams []string    `json:"teams"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &primaryResp)
+	assert.Equal(t, "multi-guild", primaryResp.User.TeamName)
+	assert.Equal(t, []string{"multi-guild", "multi-squad"}, primaryResp.Teams)
+
+	w = srv.post("/team/removeMember", map[string]interface{}{
+		"team_name": "multi-squad",
+		"user_id":   shared,
+		"fallback":  "remove",
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = srv.get("/users/getReview?user_id=" + shared)
+
+	var reviewResp struct {
+		PullRequests []models.PullRequest `json:"pull_requests"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &reviewResp)
+	require.Len(t, reviewResp.PullRequests, 1)
+	assert.Equal(t, "pr-multi-guild", reviewResp.PullRequests[0].ID)
+
+	w = srv.post("/users/setPrimaryTeam", map[string]interface{}{
+		"user_id":   shared,
+		"team_name": "multi-squad",
+	})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestTeamRoles(t *testing.T) {
+	srv := newTestServer(t)
+
+	const (
+		author      = "b8b8b8b8-0000-0000-0000-000000000001"
+		lead        = "b8b8b8b8-0000-0000-0000-000000000002"
+		member      = "b8b8b8b8-0000-0000-0000-000000000003"
+		observer    = "b8b8b8b8-0000-0000-0000-000000000004"
+		childAuthor = "b8b8b8b8-0000-0000-0000-000000000005"
+	)
+
+	srv.addTeam("roles-team", []handlers.UserInput{
+		{UserID: author, Username: "RolesAuthor", IsActive: true},
+		{UserID: lead, Username: "RolesLead", IsActive: true, Role: "lead"},
+		{UserID: member, Username: "RolesMember", IsActive: true},
+		{UserID: observer, Username: "RolesObserver", IsActive: true, Role: "observer"},
+	})
+	srv.post("/team/add", map[string]interface{}{
+		"team_name":   "roles-child",
+		"parent_team": "roles-team",
+		"members": []map[string]interface{}{
+			{"user_id": childAuthor, "username": "RolesChildAuthor", "is_active": true},
+		},
+	})
+
+	w := srv.post("/team/setRole", map[string]interface{}{
+		"team_name": "roles-team",
+		"user_id":   member,
+		"role":      "owner",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = srv.post("/team/setLeadReviewLabels", map[string]interface{}{
+		"team_name": "roles-team",
+		"labels":    []string{"security"},
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	pr := srv.createPR("pr-roles-security", "pr-roles-security", author, "security")
+	require.Len(t, pr.Reviewers, 2)
+	assert.Equal(t, lead, pr.Reviewers[0])
+	assert.Equal(t, member, pr.Reviewers[1])
+
+	pr = srv.createPR("pr-roles-plain", "pr-roles-plain", author)
+	assert.ElementsMatch(t, []string{lead, member}, pr.Reviewers)
+
+	pr = srv.createPR("pr-roles-child", "pr-roles-child", childAuthor)
+	assert.Equal(t, []string{lead}, pr.Reviewers)
+
+	w = srv.post("/users/bulkDeactivate", map[string]interface{}{
+		"user_ids": []string{member},
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = srv.post("/users/bulkDeactivate", map[string]interface{}{
+		"user_ids":    []string{member},
+		"approved_by": member,
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = srv.post("/users/bulkDeactivate", map[string]interface{}{
+		"user_ids":    []string{member},
+		"approved_by": lead,
+		"fallback":    "lead",
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var bulkResp struct {
+		UnresolvedPRs []struct {
+			PRID string `json:"pr_id"`
+		} `json:"unresolved_prs"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &bulkResp)
+	assert.Len(t, bulkResp.UnresolvedPRs, 2)
+
+	w = srv.post("/team/removeMember", map[string]interface{}{
+		"team_name": "roles-team",
+		"user_id":   observer,
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = srv.post("/team/archive", map[string]interface{}{"team_name": "roles-team"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = srv.post("/team/delete", map[string]interface{}{"team_name": "roles-team"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestTeamSettings(t *testing.T) {
+	srv := newTestServer(t)
+
+	const (
+		author     = "c9c9c9c9-0000-0000-0000-000000000001"
+		reviewer1  = "c9c9c9c9-0000-0000-0000-000000000002"
+		reviewer2  = "c9c9c9c9-0000-0000-0000-000000000003"
+		reviewer3  = "c9c9c9c9-0000-0000-0000-000000000004"
+		loneAuthor = "c9c9c9c9-0000-0000-0000-000000000005"
+	)
+
+	srv.addTeam("settings-big", []handlers.UserInput{
+		{UserID: author, Username: "SettingsAuthor", IsActive: true},
+		{UserID: reviewer1, Username: "SettingsReviewer1", IsActive: true},
+		{UserID: reviewer2, Username: "SettingsReviewer2", IsActive: true},
+		{UserID: reviewer3, Username: "SettingsReviewer3", IsActive: true},
+	})
+	srv.addTeam("settings-lone", []handlers.UserInput{
+		{UserID: loneAuthor, Username: "SettingsLoneAuthor", IsActive: true},
+	})
+
+	type settingsResponse struct {
+		Version  int                 `json:"version"`
+		Settings models.TeamSettings `json:"settings"`
+	}
+
+	w := srv.get("/team/settings?team_name=settings-big")
+	require.Equal(t, http.StatusOK, w.Code)
+	var settingsResp settingsResponse
+	json.Unmarshal(w.Body.Bytes(), &settingsResp)
+	assert.Equal(t, 0, settingsResp.Version)
+	assert.Equal(t, models.DefaultTeamSettings(), settingsResp.Settings)
+
+	w = srv.post("/team/settings/update", map[string]interface{}{
+		"team_name": "settings-big",
+		"version":   0,
+		"settings":  map[string]interface{}{"reviewer_count": 3, "selection_strategy": "least_loaded"},
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &settingsResp)
+	assert.Equal(t, 1, settingsResp.Version)
+	assert.Equal(t, 3, settingsResp.Settings.ReviewerCount)
+	assert.Equal(t, models.ApprovalLeadIfPresent, settingsResp.Settings.ApprovalPolicy)
+
+	w = srv.post("/team/settings/update", map[string]interface{}{
+		"team_name": "settings-big",
+		"version":   0,
+		"settings":  map[string]interface{}{"reviewer_count": 1},
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = srv.post("/team/settings/update", map[string]interface{}{
+		"team_name": "settings-big",
+		"settings":  map[string]interface{}{"selection_strategy": "round_robin"},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	pr := srv.createPR("pr-settings-big", "Settings PR", author)
+	assert.ElementsMatch(t, []string{reviewer1, reviewer2, reviewer3}, pr.Reviewers)
+
+	var big models.Team
+	json.Unmarshal(srv.get("/team/get?team_name=settings-big").Body.Bytes(), &big)
+	require.NotEmpty(t, big.ID)
+
+	w = srv.post("/team/settings/update", map[string]interface{}{
+		"team_name": "settings-lone",
+		"settings":  map[string]interface{}{"reviewer_count": 1, "fallback_team_ids": []string{big.ID}},
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	pr = srv.createPR("pr-settings-lone", "Lone PR", loneAuthor)
+	require.Len(t, pr.Reviewers, 1)
+	assert.Contains(t, []string{author, reviewer1, reviewer2, reviewer3}, pr.Reviewers[0])
+
+	w = srv.get("/team/settings/history?team_name=settings-big")
+	require.Equal(t, http.StatusOK, w.Code)
+	var historyResp struct {
+		Versions []settingsResponse `json:"versions"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &historyResp)
+	require.Len(t, historyResp.Versions, 1)
+	assert.Equal(t, 1, historyResp.Versions[0].Version)
+}
+
+func TestCreatePRWithMaxReviewers(t *testing.T) {
+	srv := newTestServer(t)
+
+	const author = "cacacaca-0000-0000-0000-000000000001"
+	members := []handlers.UserInput{
+		{UserID: author, Username: "WideAuthor", IsActive: true},
+	}
+	var reviewers []string
+	for i := 2; i <= 7; i++ {
+		id := fmt.Sprintf("cacacaca-0000-0000-0000-00000000000%d", i)
+		reviewers = append(reviewers, id)
+		members = append(members, handlers.UserInput{UserID: id, Username: fmt.Sprintf("WideReviewer%d", i), IsActive: true})
+	}
+	srv.addTeam("wide-team", members)
+
+	w := srv.post("/team/settings/update", map[string]interface{}{
+		"team_name": "wide-team",
+		"settings":  map[string]interface{}{"reviewer_count": models.MaxReviewers},
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	pr := srv.createPR("pr-wide-1", "Wide PR", author)
+	assert.Len(t, pr.Reviewers, models.MaxReviewers)
+	assert.Subset(t, reviewers, pr.Reviewers)
+	assert.NotContains(t, pr.Reviewers, author)
+}
+
+func TestArchiveAndDeleteTeam(t *testing.T) {
+	srv := newTestServer(t)
+
+	const (
+		archivedMember = "f6f6f6f6-0000-0000-0000-000000000001"
+		author         = "f6f6f6f6-0000-0000-0000-000000000002"
+		reviewer       = "f6f6f6f6-0000-0000-0000-000000000003"
+		target         = "f6f6f6f6-0000-0000-0000-000000000004"
+	)
+
+	srv.addTeam("archive-team", []handlers.UserInput{
+		{UserID: archivedMember, Username: "ArchiveMember", IsActive: true},
+	})
+
+	w := srv.post("/team/archive", map[string]interface{}{"team_name": "archive-team"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var archiveResp struct {
+		ArchivedAt       *string  `json:"archived_at"`
+		DeactivatedUsers []string `json:"deactivated_users"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &archiveResp)
+	assert.NotNil(t, archiveResp.ArchivedAt)
+	assert.Equal(t, []string{archivedMember}, archiveResp.DeactivatedUsers)
+
+	w = srv.post("/team/addMembers", map[string]interface{}{
+		"team_name": "archive-team",
+		"members": []map[string]interface{}{
+			{"user_id": "f6f6f6f6-0000-0000-0000-000000000005", "username": "Late", "is_active": true},
+		},
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	srv.addTeam("delete-team", []handlers.UserInput{
+		{UserID: author, Username: "DeleteAuthor", IsActive: true},
+		{UserID: reviewer, Username: "DeleteReviewer", IsActive: true},
+	})
+	srv.addTeam("delete-target", []handlers.UserInput{
+		{UserID: target, Username: "DeleteTarget", IsActive: true},
+	})
+	srv.createPR("pr-delete-1", "Delete PR", author)
+
+	w = srv.post("/team/delete", map[string]interface{}{"team_name": "delete-team"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = srv.post("/team/delete", map[string]interface{}{
+		"team_name":   "delete-team",
+		"target_team": "delete-target",
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var deleteResp struct {
+		DetachedUsers []string `json:"detached_users"`
+		MovedPRs      []string `json:"moved_prs"`
+		ReassignedPRs []struct {
+			PRID         string   `json:"pr_id"`
+			NewReviewers []string `json:"new_reviewers"`
+		} `json:"reassigned_prs"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &deleteResp)
+	assert.Len(t, deleteResp.DetachedUsers, 2)
+	assert.Equal(t, []string{"pr-delete-1"}, deleteResp.MovedPRs)
+	require.Len(t, deleteResp.ReassignedPRs, 1)
+	assert.Equal(t, []string{target}, deleteResp.ReassignedPRs[0].NewReviewers)
+
+	w = srv.get("/team/get?team_name=delete-team")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRenameTeam(t *testing.T) {
+	srv := newTestServer(t)
+
+	w := srv.post("/team/add", map[string]interface{}{
+		"team_name": "rename-old",
+		"members": []map[string]interface{}{
+			{"user_id": "a7a7a7a7-0000-0000-0000-000000000001", "username": "RenameAuthor", "is_active": true},
+			{"user_id": "a7a7a7a7-0000-0000-0000-000000000002", "username": "RenameReviewer", "is_active": true},
+		},
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created struct {
+		Team models.Team `json:"team"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	require.NotEmpty(t, created.Team.ID)
+
+	w = srv.post("/team/rename", map[string]interface{}{
+		"team_name":     "rename-old",
+		"new_team_name": "rename-new",
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = srv.get("/team/get?team_name=rename-old")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = srv.get("/team/get?team_id=" + created.Team.ID)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var team models.Team
+	json.Unmarshal(w.Body.Bytes(), &team)
+	assert.Equal(t, "rename-new", team.Name)
+	assert.Len(t, team.Members, 2)
+
+	srv.createPR("pr-rename-1", "Rename PR", "a7a7a7a7-0000-0000-0000-000000000001")
+}
+
+func TestTeamHierarchy(t *testing.T) {
+	srv := newTestServer(t)
+
+	const (
+		author   = "b8b8b8b8-0000-0000-0000-000000000001"
+		reviewer = "b8b8b8b8-0000-0000-0000-000000000002"
+		spare    = "b8b8b8b8-0000-0000-0000-000000000003"
+	)
+
+	srv.addTeam("hier-dept", []handlers.UserInput{})
+	w := srv.post("/team/add", map[string]interface{}{
+		"team_name":   "hier-squad-a",
+		"parent_team": "hier-dept",
+		"members": []map[string]interface{}{
+			{"user_id": author, "username": "HierAuthor", "is_active": true},
+			{"user_id": reviewer, "username": "HierReviewer", "is_active": true},
+		},
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+	srv.addTeam("hier-squad-b", []handlers.UserInput{
+		{UserID: spare, Username: "HierSpare", IsActive: true},
+	})
+
+	w = srv.post("/team/setParent", map[string]interface{}{"team_name": "hier-squad-b", "parent_team": "hier-dept"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = srv.post("/team/setParent", map[string]interface{}{"team_name": "hier-dept", "parent_team": "hier-squad-a"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = srv.get("/team/get?team_name=hier-dept&include_subteams=true")
+
+	var dept models.Team
+	json.Unmarshal(w.Body.Bytes(), &dept)
+	assert.Len(t, dept.SubTeams, 2)
+
+	srv.createPR("pr-hier-1", "Hierarchy PR", author)
+
+	w = srv.post("/users/bulkDeactivate", map[string]interface{}{
+		"user_ids": []string{reviewer},
+		"fallback": "department",
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var bulkResp struct {
+		ReassignedPRs []struct {
+			NewReviewers []string `json:"new_reviewers"`
+			Fallback     string   `json:"fallback"`
+		} `json:"reassigned_prs"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &bulkResp)
+	require.Len(t, bulkResp.ReassignedPRs, 1)
+	assert.Equal(t, []string{spare}, bulkResp.ReassignedPRs[0].NewReviewers)
+	assert.Equal(t, "department", bulkResp.ReassignedPRs[0].Fallback)
+
+	w = srv.get("/statistics?team_name=hier-dept&include_subteams=true")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var statsResp struct {
+		UserAssignments []struct {
+			UserID string
+		} `json:"user_assignments"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &statsResp)
+	assert.Len(t, statsResp.UserAssignments, 3)
+}
+
+func TestTeamSync(t *testing.T) {
+	srv := newTestServer(t)
+
+	sync := func(query, manifest string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/team/sync"+query, bytes.NewReader([]byte(manifest)))
+		req.Header.Set("Content-Type", "application/yaml")
+		w := httptest.NewRecorder()
+		srv.handler.ServeHTTP(w, req)
+		return w
+	}
+	getTeam := func(name string) models.Team {
+		w := srv.get("/team/get?team_name=" + name)
+		var team models.Team
+		json.Unmarshal(w.Body.Bytes(), &team)
+		return team
+	}
+
+	const (
+		author    = "d1d1d1d1-0000-0000-0000-000000000001"
+		stayer    = "d1d1d1d1-0000-0000-0000-000000000002"
+		mover     = "d1d1d1d1-0000-0000-0000-000000000003"
+		leaver    = "d1d1d1d1-0000-0000-0000-000000000004"
+		other     = "d1d1d1d1-0000-0000-0000-000000000005"
+		newcomer  = "d1d1d1d1-0000-0000-0000-000000000006"
+		latecomer = "d1d1d1d1-0000-0000-0000-000000000007"
+	)
+
+	srv.addTeam("sync-a", []handlers.UserInput{
+		{UserID: author, Username: "SyncAuthor", IsActive: true},
+		{UserID: stayer, Username: "SyncStayer", IsActive: true},
+		{UserID: mover, Username: "SyncMover", IsActive: true},
+		{UserID: leaver, Username: "SyncLeaver", IsActive: true},
+	})
+	srv.addTeam("sync-b", []handlers.UserInput{
+		{UserID: other, Username: "SyncOther", IsActive: true},
+	})
+	srv.post("/team/settings/update", map[string]interface{}{
+		"team_name": "sync-a",
+		"settings":  map[string]interface{}{"reviewer_count": 3},
+	})
+	srv.createPR("pr-sync-1", "Sync PR", author)
+
+	manifest := `
+teams:
+  - name: sync-a
+    members:
+      - {user_id: ` + author + `, username: SyncAuthor, role: lead}
+      - {user_id: ` + stayer + `, username: SyncStayer}
+  - name: sync-b
+    members:
+      - {user_id: ` + other + `, username: SyncOther}
+      - {user_id: ` + mover + `, username: SyncMover}
+  - name: sync-c
+    parent: sync-a
+    members:
+      - {user_id: ` + newcomer + `, username: SyncNewcomer}
+`
+
+	type syncResponse struct {
+		Actions []struct {
+			Action   string `json:"action"`
+			TeamName string `json:"team_name"`
+			UserID   string `json:"user_id"`
+			FromTeam string `json:"from_team"`
+		} `json:"actions"`
+		ReassignedPRs []struct {
+			PRID    string   `json:"pr_id"`
+			Removed []string `json:"removed"`
+		} `json:"reassigned_prs"`
+		PlanToken string `json:"plan_token"`
+	}
+
+	w := sync("?dry_run=true&fallback=remove", manifest)
+	require.Equal(t, http.StatusOK, w.Code)
+	var plan syncResponse
+	json.Unmarshal(w.Body.Bytes(), &plan)
+	require.NotEmpty(t, plan.PlanToken)
+
+	kinds := map[string]string{}
+	for _, a := range plan.Actions {
+		kinds[a.Action+":"+a.UserID+a.TeamName] = a.FromTeam
+	}
+	assert.Contains(t, kinds, "create_team:sync-c")
+	assert.Contains(t, kinds, "create_user:"+newcomer)
+	assert.Contains(t, kinds, "add_member:"+newcomer+"sync-c")
+	assert.Contains(t, kinds, "set_role:"+author+"sync-a")
+	assert.Equal(t, "sync-a", kinds["move_member:"+mover+"sync-b"])
+	assert.Contains(t, kinds, "remove_member:"+leaver+"sync-a")
+	assert.Contains(t, kinds, "deactivate_user:"+leaver)
+	require.Len(t, plan.ReassignedPRs, 1)
+	assert.ElementsMatch(t, []string{mover, leaver}, plan.ReassignedPRs[0].Removed)
+
+	assert.Len(t, getTeam("sync-a").Members, 4)
+
+	w = sync("?fallback=remove&plan_token="+plan.PlanToken, manifest)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	teamA := getTeam("sync-a")
+	require.Len(t, teamA.Members, 2)
+	assert.Equal(t, models.TeamRoleLead, teamA.Members[0].Role)
+	assert.Len(t, getTeam("sync-b").Members, 2)
+	assert.Equal(t, teamA.ID, getTeam("sync-c").ParentID)
+
+	w = sync("?dry_run=true", manifest)
+	json.Unmarshal(w.Body.Bytes(), &plan)
+	assert.Empty(t, plan.Actions)
+
+	srv.post("/team/addMembers", map[string]interface{}{
+		"team_name": "sync-a",
+		"members": []map[string]interface{}{
+			{"user_id": latecomer, "username": "SyncLatecomer", "is_active": true},
+		},
+	})
+	w = sync("?plan_token="+plan.PlanToken, manifest)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = sync("", "teams:\n  - name: sync-a\n    member: []\n")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestTeamCSVImportExport(t *testing.T) {
+	srv := newTestServer(t)
+
+	importCSV := func(query, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/team/import"+query, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		srv.handler.ServeHTTP(w, req)
+		return w
+	}
+
+	const (
+		alice = "e2e2e2e2-0000-0000-0000-000000000001"
+		bob   = "e2e2e2e2-0000-0000-0000-000000000002"
+		carol = "e2e2e2e2-0000-0000-0000-000000000003"
+	)
+
+	w := importCSV("?create_teams=true", "team_name,user_id,username,role,is_active\n"+
+		"csv-backend,"+alice+",CSVAlice,lead,true\n"+
+		"csv-backend,"+bob+",CSVBob,,\n"+
+		"csv-frontend,"+bob+",CSVBob,member,true\n"+
+		"csv-frontend,"+carol+",CSVCarol,,false\n")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var importResp struct {
+		CreatedTeams []string `json:"created_teams"`
+		Users        int      `json:"users"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &importResp)
+	assert.Equal(t, []string{"csv-backend", "csv-frontend"}, importResp.CreatedTeams)
+	assert.Equal(t, 3, importResp.Users)
+
+	w = importCSV("", "team_name,user_id,username\n"+
+		"csv-backend,not-a-uuid,CSVDave\n"+
+		"csv-unknown,e2e2e2e2-0000-0000-0000-000000000004,CSVErin\n"+
+		"csv-frontend,e2e2e2e2-0000-0000-0000-000000000005,CSVAlice\n")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	var errResp struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+		Rows []struct {
+			Line int `json:"line"`
+		} `json:"rows"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &errResp)
+	assert.Equal(t, "INVALID_ROWS", errResp.Error.Code)
+	require.Len(t, errResp.Rows, 3)
+	assert.Equal(t, 2, errResp.Rows[0].Line)
+	assert.Equal(t, 3, errResp.Rows[1].Line)
+	assert.Equal(t, 4, errResp.Rows[2].Line)
+
+	w = importCSV("", "team,user\n")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = srv.get("/team/export")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+
+	body := w.Body.String()
+	assert.True(t, strings.HasPrefix(body, "team_name,user_id,username,role,is_active\n"))
+	assert.Contains(t, body, "csv-backend,"+alice+",CSVAlice,lead,true\n")
+	assert.Contains(t, body, "csv-frontend,"+carol+",CSVCarol,member,false\n")
+}
+
+func TestUserDirectory(t *testing.T) {
+	srv := newTestServer(t)
+
+	const (
+		anna   = "f3f3f3f3-0000-0000-0000-000000000001"
+		boris  = "f3f3f3f3-0000-0000-0000-000000000002"
+		anton  = "f3f3f3f3-0000-0000-0000-000000000003"
+		nobody = "f3f3f3f3-0000-0000-0000-000000000009"
+	)
+
+	srv.addTeam("dir-team", []handlers.UserInput{
+		{UserID: anna, Username: "DirAnna", IsActive: true, Role: "lead"},
+		{UserID: boris, Username: "DirBoris", IsActive: true},
+		{UserID: anton, Username: "DirAnton"},
+	})
+	srv.createPR("pr-dir-1", "Directory PR", anna)
+
+	type profile struct {
+		UserID          string   `json:"user_id"`
+		TeamName        string   `json:"team_name"`
+		Teams           []string `json:"teams"`
+		OpenReviews     int      `json:"open_reviews"`
+		AuthoredOpenPRs []string `json:"authored_open_prs"`
+	}
+
+	w := srv.get("/users/get?user_id=" + boris)
+	require.Equal(t, http.StatusOK, w.Code)
+	var getResp struct {
+		User profile `json:"user"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &getResp)
+	assert.Equal(t, "dir-team", getResp.User.TeamName)
+	assert.Equal(t, []string{"dir-team"}, getResp.User.Teams)
+	assert.Equal(t, 1, getResp.User.OpenReviews)
+	assert.Empty(t, getResp.User.AuthoredOpenPRs)
+
+	w = srv.get("/users/get?user_id=" + anna)
+	json.Unmarshal(w.Body.Bytes(), &getResp)
+	assert.Equal(t, []string{"pr-dir-1"}, getResp.User.AuthoredOpenPRs)
+
+	assert.Equal(t, http.StatusNotFound, srv.get("/users/get?user_id="+nobody).Code)
+	assert.Equal(t, http.StatusBadRequest, srv.get("/users/get?user_id=bad").Code)
+
+	type listResponse struct {
+		Users []profile `json:"users"`
+		Total int       `json:"total"`
+	}
+	list := func(query string) listResponse {
+		w := srv.get("/users/list?" + query)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp listResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+
+	assert.Equal(t, 3, list("team_name=dir-team").Total)
+	assert.Equal(t, 2, list("team_name=dir-team&name_prefix=diran").Total)
+	assert.Equal(t, 1, list("team_name=dir-team&name_prefix=diran&is_active=true").Total)
+
+	leads := list("team_name=dir-team&role=lead")
+	require.Len(t, leads.Users, 1)
+	assert.Equal(t, anna, leads.Users[0].UserID)
+
+	page := list("team_name=dir-team&limit=1&offset=1")
+	assert.Equal(t, 3, page.Total)
+	require.Len(t, page.Users, 1)
+	assert.Equal(t, anton, page.Users[0].UserID)
+
+	assert.Equal(t, http.StatusBadRequest, srv.get("/users/list?limit=1000").Code)
+	assert.Equal(t, http.StatusBadRequest, srv.get("/users/list?role=owner").Code)
+}
+
+func TestAuthoredPullRequests(t *testing.T) {
+	srv := newTestServer(t)
+
+	review := func(prID, reviewerID, verdict string) *httptest.ResponseRecorder {
+		return srv.post("/pullRequest/review", map[string]interface{}{
+			"pull_request_id": prID,
+			"reviewer_id":     reviewerID,
+			"verdict":         verdict,
+		})
+	}
+
+	const (
+		author = "f4f4f4f4-0000-0000-0000-000000000001"
+		vera   = "f4f4f4f4-0000-0000-0000-000000000002"
+		gleb   = "f4f4f4f4-0000-0000-0000-000000000003"
+		nobody = "f4f4f4f4-0000-0000-0000-000000000009"
+	)
+
+	srv.addTeam("authored-team", []handlers.UserInput{
+		{UserID: author, Username: "AuthAuthor", IsActive: true},
+		{UserID: vera, Username: "AuthVera", IsActive: true},
+		{UserID: gleb, Username: "AuthGleb", IsActive: true},
+	})
+	for _, id := range []string{"pr-auth-1", "pr-auth-2", "pr-auth-3"} {
+		srv.createPR(id, "Authored "+id, author)
+	}
+
+	assert.Equal(t, http.StatusOK, review("pr-auth-1", vera, "APPROVED").Code)
+	assert.Equal(t, http.StatusOK, review("pr-auth-1", gleb, "APPROVED").Code)
+	assert.Equal(t, http.StatusOK, review("pr-auth-2", vera, "CHANGES_REQUESTED").Code)
+	assert.Equal(t, http.StatusBadRequest, review("pr-auth-2", vera, "LGTM").Code)
+	assert.Equal(t, http.StatusConflict, review("pr-auth-2", author, "APPROVED").Code)
+	assert.Equal(t, http.StatusNotFound, review("pr-missing", vera, "APPROVED").Code)
+
+	w := srv.post("/pullRequest/merge", map[string]string{"pull_request_id": "pr-auth-3"})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusConflict, review("pr-auth-3", vera, "APPROVED").Code)
+
+	type reviewer struct {
+		UserID    string  `json:"user_id"`
+		Verdict   string  `json:"verdict"`
+		DecidedAt *string `json:"decided_at"`
+	}
+	type authoredResponse struct {
+		PullRequests []struct {
+			PullRequestID string     `json:"pull_request_id"`
+			Status        string     `json:"status"`
+			Blocked       bool       `json:"blocked"`
+			Reviewers     []reviewer `json:"reviewers"`
+		} `json:"pull_requests"`
+		Summary struct {
+			Open      int `json:"open"`
+			Merged    int `json:"merged"`
+			Blocked   int `json:"blocked"`
+			BlockedOn []struct {
+				UserID           string   `json:"user_id"`
+				PRs              int      `json:"prs"`
+				Pending          int      `json:"pending"`
+				ChangesRequested int      `json:"changes_requested"`
+				PullRequestIDs   []string `json:"pull_request_ids"`
+			} `json:"blocked_on"`
+		} `json:"summary"`
+	}
+
+	w = srv.get("/users/getAuthored?user_id=" + author)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp authoredResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	require.Len(t, resp.PullRequests, 3)
+	assert.Equal(t, 2, resp.Summary.Open)
+	assert.Equal(t, 1, resp.Summary.Merged)
+	assert.Equal(t, 1, resp.Summary.Blocked)
+
+	verdicts := map[string]map[string]string{}
+	for _, pr := range resp.PullRequests {
+		verdicts[pr.PullRequestID] = map[string]string{}
+		for _, r := range pr.Reviewers {
+			verdicts[pr.PullRequestID][r.UserID] = r.Verdict
+			assert.Equal(t, r.Verdict != "PENDING", r.DecidedAt != nil)
+		}
+		assert.Equal(t, pr.PullRequestID == "pr-auth-2", pr.Blocked)
+	}
+	assert.Equal(t, map[string]string{vera: "APPROVED", gleb: "APPROVED"}, verdicts["pr-auth-1"])
+	assert.Equal(t, map[string]string{vera: "CHANGES_REQUESTED", gleb: "PENDING"}, verdicts["pr-auth-2"])
+
+	require.Len(t, resp.Summary.BlockedOn, 2)
+	for _, b := range resp.Summary.BlockedOn {
+		assert.Equal(t, 1, b.PRs)
+		assert.Equal(t, []string{"pr-auth-2"}, b.PullRequestIDs)
+		if b.UserID == vera {
+			assert.Equal(t, 1, b.ChangesRequested)
+		} else {
+			assert.Equal(t, gleb, b.UserID)
+			assert.Equal(t, 1, b.Pending)
+		}
+	}
+
+	w = srv.get("/users/getAuthored?status=MERGED&user_id=" + author)
+	require.Equal(t, http.StatusOK, w.Code)
+	resp = authoredResponse{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	require.Len(t, resp.PullRequests, 1)
+	assert.Equal(t, "pr-auth-3", resp.PullRequests[0].PullRequestID)
+	assert.Empty(t, resp.Summary.BlockedOn)
+
+	assert.Equal(t, http.StatusNotFound, srv.get("/users/getAuthored?user_id="+nobody).Code)
+	assert.Equal(t, http.StatusBadRequest, srv.get("/users/getAuthored?user_id=bad").Code)
+	assert.Equal(t, http.StatusBadRequest, srv.get("/users/getAuthored?status=CLOSED&user_id="+author).Code)
+}
+
+func TestEraseUser(t *testing.T) {
+	srv := newTestServer(t)
+
+	const (
+		author   = "f5f5f5f5-0000-0000-0000-000000000001"
+		leaving  = "f5f5f5f5-0000-0000-0000-000000000002"
+		reviewer = "f5f5f5f5-0000-0000-0000-000000000003"
+		newcomer = "f5f5f5f5-0000-0000-0000-000000000004"
+	)
+
+	srv.addTeam("erase-team", []handlers.UserInput{
+		{UserID: author, Username: "EraseAuthor", IsActive: true},
+		{UserID: leaving, Username: "EraseLeaving", IsActive: true},
+		{UserID: reviewer, Username: "EraseReviewer", IsActive: true},
+	})
+	srv.createPR("pr-erase-1", "Reviewed by the leaving user", author)
+	srv.createPR("pr-erase-2", "Authored by the leaving user", leaving)
+	w := srv.post("/team/addMembers", map[string]interface{}{
+		"team_name": "erase-team",
+		"members": []map[string]interface{}{
+			{"user_id": newcomer, "username": "EraseNewcomer", "is_active": true},
+		},
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = srv.post("/users/erase", map[string]interface{}{"user_id": leaving, "reason": "left the company"})
+	require.Equal(t, http.StatusOK, w.Code)
+	var eraseResp struct {
+		Erasure struct {
+			UserID          string `json:"user_id"`
+			Pseudonym       string `json:"pseudonym"`
+			Reason          string `json:"reason"`
+			ReassignedCount int    `json:"reassigned_count"`
+		} `json:"erasure"`
+		ReassignedPRs []struct {
+			PRID         string   `json:"pr_id"`
+			Replaced     []string `json:"replaced"`
+			NewReviewers []string `json:"new_reviewers"`
+		} `json:"reassigned_prs"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &eraseResp)
+	assert.Equal(t, leaving, eraseResp.Erasure.UserID)
+	assert.Equal(t, "left the company", eraseResp.Erasure.Reason)
+	assert.Equal(t, 1, eraseResp.Erasure.ReassignedCount)
+	require.Len(t, eraseResp.ReassignedPRs, 1)
+	assert.Equal(t, "pr-erase-1", eraseResp.ReassignedPRs[0].PRID)
+	assert.Equal(t, []string{newcomer}, eraseResp.ReassignedPRs[0].NewReviewers)
+	pseudonym := eraseResp.Erasure.Pseudonym
+	assert.True(t, strings.HasPrefix(pseudonym, "erased-"))
+
+	w = srv.get("/users/get?user_id=" + leaving)
+	require.Equal(t, http.StatusOK, w.Code)
+	var profile struct {
+		User struct {
+			Username string   `json:"username"`
+			IsActive bool     `json:"is_active"`
+			Teams    []string `json:"teams"`
+		} `json:"user"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &profile)
+	assert.Equal(t, pseudonym, profile.User.Username)
+	assert.False(t, profile.User.IsActive)
+	assert.Empty(t, profile.User.Teams)
+	assert.NotContains(t, srv.get("/team/get?team_name=erase-team").Body.String(), "EraseLeaving")
+
+	w = srv.get("/users/getAuthored?user_id=" + leaving)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "pr-erase-2")
+
+	assert.Equal(t, http.StatusConflict, srv.post("/users/erase", map[string]interface{}{"user_id": leaving}).Code)
+	assert.Equal(t, http.StatusNotFound, srv.post("/users/setIsActive", map[string]interface{}{"user_id": leaving, "is_active": true}).Code)
+
+	w = srv.get("/users/erasures?user_id=" + leaving)
+	require.Equal(t, http.StatusOK, w.Code)
+	var auditResp struct {
+		Erasures []struct {
+			Pseudonym string `json:"pseudonym"`
+		} `json:"erasures"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &auditResp)
+	require.Len(t, auditResp.Erasures, 1)
+	assert.Equal(t, pseudonym, auditResp.Erasures[0].Pseudonym)
+	assert.NotContains(t, w.Body.String(), "EraseLeaving")
+}
+
+func TestTeamStatistics(t *testing.T) {
+	srv := newTestServer(t)
+
+	const (
+		author = "f6f6f6f6-0000-0000-0000-000000000001"
+		first  = "f6f6f6f6-0000-0000-0000-000000000002"
+		second = "f6f6f6f6-0000-0000-0000-000000000003"
+		other  = "f6f6f6f6-0000-0000-0000-000000000004"
+	)
+
+	srv.addTeam("team-stats-a", []handlers.UserInput{
+		{UserID: author, Username: "TeamStatsAuthor", IsActive: true},
+		{UserID: first, Username: "TeamStatsFirst", IsActive: true},
+		{UserID: second, Username: "TeamStatsSecond", IsActive: true},
+	})
+	srv.addTeam("team-stats-b", []handlers.UserInput{
+		{UserID: other, Username: "TeamStatsOther", IsActive: true},
+	})
+	for _, id := range []string{"pr-team-stats-1", "pr-team-stats-2"} {
+		srv.createPR(id, "Team stats "+id, author)
+	}
+	require.Equal(t, http.StatusOK, srv.post("/pullRequest/merge", map[string]string{"pull_request_id": "pr-team-stats-1"}).Code)
+
+	type teamStats struct {
+		TeamName          string  `json:"team_name"`
+		OpenedPRs         int     `json:"opened_prs"`
+		OpenPRs           int     `json:"open_prs"`
+		MergedPRs         int     `json:"merged_prs"`
+		Assignments       int     `json:"assignments"`
+		AvgReviewersPerPR float64 `json:"avg_reviewers_per_pr"`
+		Members           []struct {
+			UserID      string `json:"user_id"`
+			Assignments int    `json:"assignments"`
+		} `json:"members"`
+	}
+	var resp struct {
+		Teams []teamStats `json:"teams"`
+	}
+
+	w := srv.get("/statistics/teams?team_name=team-stats-a")
+	require.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	require.Len(t, resp.Teams, 1)
+
+	team := resp.Teams[0]
+	assert.Equal(t, "team-stats-a", team.TeamName)
+	assert.Equal(t, 2, team.OpenedPRs)
+	assert.Equal(t, 1, team.OpenPRs)
+	assert.Equal(t, 1, team.MergedPRs)
+	assert.Equal(t, 4, team.Assignments)
+	assert.Equal(t, 2.0, team.AvgReviewersPerPR)
+
+	assignments := map[string]int{}
+	for _, m := range team.Members {
+		assignments[m.UserID] = m.Assignments
+	}
+	assert.Equal(t, map[string]int{author: 0, first: 2, second: 2}, assignments)
+
+	w = srv.get("/statistics/teams")
+	require.Equal(t, http.StatusOK, w.Code)
+	resp.Teams = nil
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	names := map[string]bool{}
+	for _, team := range resp.Teams {
+		names[team.TeamName] = true
+	}
+	assert.True(t, names["team-stats-a"])
+	assert.True(t, names["team-stats-b"])
+
+	assert.Equal(t, http.StatusNotFound, srv.get("/statistics/teams?team_name=team-stats-missing").Code)
+	assert.Equal(t, http.StatusBadRequest, srv.get("/statistics/teams?to=2000-01-01&from=2001-01-01").Code)
+}
+
+func TestLatencyStatistics(t *testing.T) {
+	srv := newTestServer(t)
+
+	const (
+		author   = "f7f7f7f7-0000-0000-0000-000000000001"
+		approver = "f7f7f7f7-0000-0000-0000-000000000002"
+		silent   = "f7f7f7f7-0000-0000-0000-000000000003"
+	)
+
+	srv.addTeam("latency-team", []handlers.UserInput{
+		{UserID: author, Username: "LatencyAuthor", IsActive: true},
+		{UserID: approver, Username: "LatencyApprover", IsActive: true},
+		{UserID: silent, Username: "LatencySilent", IsActive: true},
+	})
+	for _, id := range []string{"pr-latency-1", "pr-latency-2"} {
+		srv.createPR(id, "Latency "+id, author)
+	}
+	require.Equal(t, http.StatusOK, srv.post("/pullRequest/review", map[string]string{
+		"pull_request_id": "pr-latency-1",
+		"reviewer_id":     approver,
+		"verdict":         "APPROVED",
+	}).Code)
+	require.Equal(t, http.StatusOK, srv.post("/pullRequest/merge", map[string]string{"pull_request_id": "pr-latency-1"}).Code)
+
+	type percentiles struct {
+		Count int     `json:"count"`
+		P50   float64 `json:"p50"`
+		P99   float64 `json:"p99"`
+	}
+	type latency struct {
+		TeamName     string      `json:"team_name"`
+		UserID       string      `json:"user_id"`
+		ToAssignment percentiles `json:"to_assignment"`
+		ToVerdict    percentiles `json:"to_verdict"`
+		ToMerge      percentiles `json:"to_merge"`
+	}
+	var resp struct {
+		Overall   latency   `json:"overall"`
+		Teams     []latency `json:"teams"`
+		Reviewers []latency `json:"reviewers"`
+	}
+
+	w := srv.get("/statistics/latency?team_name=latency-team")
+	require.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	assert.Equal(t, 2, resp.Overall.ToAssignment.Count)
+	assert.Equal(t, 1, resp.Overall.ToVerdict.Count)
+	assert.Equal(t, 1, resp.Overall.ToMerge.Count)
+	assert.GreaterOrEqual(t, resp.Overall.ToMerge.P50, 0.0)
+	assert.LessOrEqual(t, resp.Overall.ToAssignment.P50, resp.Overall.ToAssignment.P99)
+
+	require.Len(t, resp.Teams, 1)
+	assert.Equal(t, "latency-team", resp.Teams[0].TeamName)
+	assert.Equal(t, resp.Overall.ToMerge.Count, resp.Teams[0].ToMerge.Count)
+
+	verdicts := map[string]int{}
+	for _, reviewer := range resp.Reviewers {
+		assert.Equal(t, 2, reviewer.ToAssignment.Count)
+		verdicts[reviewer.UserID] = reviewer.ToVerdict.Count
+	}
+	assert.Equal(t, map[string]int{approver: 1, silent: 0}, verdicts)
+
+	assert.Equal(t, http.StatusNotFound, srv.get("/statistics/latency?team_name=latency-missing").Code)
+}
+
+func TestFairnessStatistics(t *testing.T) {
+	srv := newTestServer(t)
+
+	const (
+		author   = "f8f8f8f8-0000-0000-0000-000000000001"
+		first    = "f8f8f8f8-0000-0000-0000-000000000002"
+		second   = "f8f8f8f8-0000-0000-0000-000000000003"
+		away     = "f8f8f8f8-0000-0000-0000-000000000004"
+		observer = "f8f8f8f8-0000-0000-0000-000000000005"
+	)
+
+	srv.addTeam("fairness-team", []handlers.UserInput{
+		{UserID: author, Username: "FairAuthor", IsActive: true},
+		{UserID: first, Username: "FairFirst", IsActive: true},
+		{UserID: second, Username: "FairSecond", IsActive: true},
+		{UserID: away, Username: "FairAway"},
+		{UserID: observer, Username: "FairObserver", IsActive: true, Role: "observer"},
+	})
+	for _, id := range []string{"pr-fair-1", "pr-fair-2"} {
+		srv.createPR(id, "Fairness "+id, author)
+	}
+
+	type fairness struct {
+		TeamName    string   `json:"team_name"`
+		Gini        float64  `json:"gini"`
+		MaxMinRatio *float64 `json:"max_min_ratio"`
+		Members     []struct {
+			UserID      string  `json:"user_id"`
+			Assignments int     `json:"assignments"`
+			ActiveDays  float64 `json:"active_days"`
+		} `json:"members"`
+		Above    []string `json:"above"`
+		Below    []string `json:"below"`
+		Excluded []string `json:"excluded"`
+	}
+	report := func(query string) fairness {
+		w := srv.get("/statistics/fairness?team_name=fairness-team" + query)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Teams []fairness `json:"teams"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		require.Len(t, resp.Teams, 1)
+		return resp.Teams[0]
+	}
+
+	team := report("")
+	assert.Equal(t, "fairness-team", team.TeamName)
+	assert.InDelta(t, 1.0/3, team.Gini, 0.01)
+	assert.Nil(t, team.MaxMinRatio)
+	assert.Equal(t, []string{author}, team.Below)
+	assert.Empty(t, team.Above)
+	assert.Equal(t, []string{away}, team.Excluded)
+
+	assignments := map[string]int{}
+	for _, m := range team.Members {
+		assignments[m.UserID] = m.Assignments
+		assert.Greater(t, m.ActiveDays, 0.0)
+	}
+	assert.Equal(t, map[string]int{author: 0, first: 2, second: 2}, assignments)
+
+	assert.Empty(t, report("&threshold=2").Below)
+
+	for _, threshold := range []string{"-1", "0", "NaN", "Inf", "-Inf"} {
+		assert.Equal(t, http.StatusBadRequest, srv.get("/statistics/fairness?threshold="+threshold).Code, threshold)
+	}
+	assert.Equal(t, http.StatusNotFound, srv.get("/statistics/fairness?team_name=fairness-missing").Code)
+}
+
+func TestTimeSeriesStatistics(t *testing.T) {
+	srv := newTestServer(t)
+
+	const (
+		author   = "f9f9f9f9-0000-0000-0000-000000000001"
+		reviewer = "f9f9f9f9-0000-0000-0000-000000000002"
+	)
+
+	srv.addTeam("series-team", []handlers.UserInput{
+		{UserID: author, Username: "SeriesAuthor", IsActive: true},
+		{UserID: reviewer, Username: "SeriesReviewer", IsActive: true},
+	})
+	for _, id := range []string{"pr-series-1", "pr-series-2"} {
+		srv.createPR(id, "Series "+id, author)
+	}
+	w := srv.post("/pullRequest/merge", map[string]interface{}{"pull_request_id": "pr-series-1"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	type point struct {
+		Start       time.Time `json:"start"`
+		OpenedPRs   int       `json:"opened_prs"`
+		MergedPRs   int       `json:"merged_prs"`
+		Assignments int       `json:"assignments"`
+	}
+	series := func(query string) []point {
+		w := srv.get("/statistics/series?team_name=series-team" + query)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Points []point `json:"points"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		require.NotEmpty(t, resp.Points)
+		return resp.Points
+	}
+
+	days := series("")
+	assert.GreaterOrEqual(t, len(days), 30)
+	last := days[len(days)-1]
+	assert.Equal(t, 2, last.OpenedPRs)
+	assert.Equal(t, 1, last.MergedPRs)
+	assert.Equal(t, 2, last.Assignments)
+	for _, p := range days[:len(days)-1] {
+		assert.Zero(t, p.OpenedPRs+p.MergedPRs+p.Assignments)
+	}
+	for i := 1; i < len(days); i++ {
+		assert.True(t, days[i-1].Start.Before(days[i].Start))
+	}
+
+	weeks := series("&bucket=week&tz=Europe/Moscow")
+	assert.Equal(t, time.Monday, weeks[0].Start.Weekday())
+	assert.Equal(t, 2, weeks[len(weeks)-1].OpenedPRs)
+
+	reviewerDays := series("&user_id=" + reviewer)
+	assert.Equal(t, 2, reviewerDays[len(reviewerDays)-1].Assignments)
+	assert.Zero(t, reviewerDays[len(reviewerDays)-1].OpenedPRs)
+
+	assert.Equal(t, http.StatusBadRequest, srv.get("/statistics/series?bucket=hour").Code)
+	assert.Equal(t, http.StatusBadRequest, srv.get("/statistics/series?tz=Nowhere/City").Code)
+	assert.Equal(t, http.StatusBadRequest, srv.get("/statistics/series?tz=Local").Code)
+
+	utc := series("&tz=")
+	assert.Equal(t, time.UTC, utc[0].Start.Location())
+	assert.Equal(t, http.StatusNotFound, srv.get("/statistics/series?team_name=no-such-team").Code)
+}
+
+func TestMetrics(t *testing.T) {
+	srv := newTestServer(t)
+
+	const (
+		author   = "fafafafa-0000-0000-0000-000000000001"
+		reviewer = "fafafafa-0000-0000-0000-000000000002"
+	)
+
+	srv.addTeam("metrics-team", []handlers.UserInput{
+		{UserID: author, Username: "MetricsAuthor", IsActive: true},
+		{UserID: reviewer, Username: "MetricsReviewer", IsActive: true},
+	})
+	srv.createPR("pr-metrics-1", "Metrics", author)
+
+	w := srv.post("/pullRequest/reassign", map[string]interface{}{
+		"pull_request_id": "pr-metrics-1",
+		"old_user_id":     reviewer,
+	})
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	assert.Equal(t, http.StatusNotFound, srv.get("/no/such/route").Code)
+
+	w = srv.get("/metrics")
+	require.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+
+	assert.Contains(t, body, "# TYPE reviewer_service_http_requests_total counter")
+	assert.Contains(t, body, `reviewer_service_http_requests_total{route="/pullRequest/create",status="201"} 1`)
+	assert.Contains(t, body, `reviewer_service_http_requests_total{route="/pullRequest/reassign",status="409"} 1`)
+	assert.Contains(t, body, `reviewer_service_http_requests_total{route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, "# TYPE reviewer_service_http_request_duration_seconds histogram")
+	assert.Contains(t, body, `reviewer_service_http_request_duration_seconds_bucket{route="/pullRequest/create",status="201",le="+Inf"} 1`)
+	assert.Contains(t, body, `reviewer_service_http_request_duration_seconds_count{route="/pullRequest/create",status="201"} 1`)
+	assert.Contains(t, body, "reviewer_service_db_open_connections ")
+	assert.Contains(t, body, "reviewer_service_db_wait_count_total ")
+	assert.Contains(t, body, "reviewer_service_assignments_made_total 1\n")
+	assert.Contains(t, body, "reviewer_service_reassignments_total 0\n")
+	assert.Contains(t, body, "reviewer_service_no_candidate_total 1\n")
+	assert.Contains(t, body, "reviewer_service_bulk_deactivations_total 0\n")
+
+	w = srv.post("/users/bulkDeactivate", map[string]interface{}{"user_ids": []string{reviewer}})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	body = srv.get("/metrics").Body.String()
+	assert.Contains(t, body, "reviewer_service_bulk_deactivations_total 1\n")
+	assert.Contains(t, body, "reviewer_service_bulk_deactivated_users_total 1\n")
+	assert.Contains(t, body, `reviewer_service_http_requests_total{route="/metrics",status="200"} 1`)
+}
+
+func TestInteractionStatistics(t *testing.T) {
+	srv := newTestServer(t)
+
+	const (
+		alice = "fbfbfbfb-0000-0000-0000-000000000001"
+		bob   = "fbfbfbfb-0000-0000-0000-000000000002"
+		carol = "fbfbfbfb-0000-0000-0000-000000000003"
+	)
+
+	srv.addTeam("interactions-team", []handlers.UserInput{
+		{UserID: alice, Username: "IntAlice", IsActive: true},
+		{UserID: bob, Username: "IntBob", IsActive: true},
+		{UserID: carol, Username: "IntCarol", IsActive: true},
+	})
+	for i, author := range []string{alice, alice, bob} {
+		srv.createPR(fmt.Sprintf("pr-interactions-%d", i), "Interactions", author)
+	}
+
+	w := srv.get("/statistics/interactions?team_name=interactions-team")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Teams []struct {
+			TeamName string `json:"team_name"`
+			Users    []struct {
+				UserID string `json:"user_id"`
+			} `json:"users"`
+			Reviews [][]int `json:"reviews"`
+		} `json:"teams"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	require.Len(t, resp.Teams, 1)
+	team := resp.Teams[0]
+	require.Len(t, team.Users, 3)
+	assert.Equal(t, []string{alice, bob, carol}, []string{team.Users[0].UserID, team.Users[1].UserID, team.Users[2].UserID})
+	assert.Equal(t, [][]int{{0, 2, 2}, {1, 0, 1}, {0, 0, 0}}, team.Reviews)
+
+	w = srv.get("/statistics/interactions?team_name=interactions-team&format=dot")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/vnd.graphviz")
+	assert.True(t, strings.HasPrefix(w.Body.String(), "digraph reviews {"))
+	assert.Contains(t, w.Body.String(), `"interactions-team/`+alice+`" -> "interactions-team/`+bob+`" [label=2, weight=2];`)
+	assert.Contains(t, w.Body.String(), `[label="IntCarol"]`)
+
+	req := httptest.NewRequest("GET", "/statistics/interactions?team_name=interactions-team", nil)
+	req.Header.Set("Accept", "application/graphml+xml")
+	w = httptest.NewRecorder()
+	srv.handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var graph struct {
+		Graphs []struct {
+			ID    string `xml:"id,attr"`
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+				Data   string `xml:"data"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &graph))
+	require.Len(t, graph.Graphs, 1)
+	assert.Equal(t, "interactions-team", graph.Graphs[0].ID)
+	assert.Len(t, graph.Graphs[0].Nodes, 3)
+	assert.Len(t, graph.Graphs[0].Edges, 4)
+
+	w = srv.get("/statistics/interactions?team_name=interactions-team&from=2000-01-01&to=2000-01-31")
+	require.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	require.Len(t, resp.Teams, 1)
+	assert.Empty(t, resp.Teams[0].Users)
+
+	assert.Equal(t, http.StatusBadRequest, srv.get("/statistics/interactions?format=png").Code)
+	assert.Equal(t, http.StatusNotFound, srv.get("/statistics/interactions?team_name=no-such-team").Code)
 }
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS bulk_operations (
+    operation_id   UUID PRIMARY KEY,
+    kind           TEXT NOT NULL,
+    team_name      TEXT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    reactivated_at TIMESTAMPTZ NULL
+);
+
+CREATE TABLE IF NOT EXISTS bulk_operation_users (
+    operation_id UUID NOT NULL REFERENCES bulk_operations(operation_id) ON DELETE CASCADE,
+    user_id      UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    PRIMARY KEY (operation_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS bulk_operation_reassignments (
+    operation_id    UUID NOT NULL REFERENCES bulk_operations(operation_id) ON DELETE CASCADE,
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    old_reviewer_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    new_reviewer_id UUID NULL REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_bulk_operation_users_user ON bulk_operation_users(user_id);
+CREATE INDEX IF NOT EXISTS idx_bulk_operation_reassignments_op ON bulk_operation_reassignments(operation_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS bulk_operation_reassignments;
+DROP TABLE IF EXISTS bulk_operation_users;
+DROP TABLE IF EXISTS bulk_operations;
+
+DROP INDEX IF EXISTS idx_bulk_operation_users_user;
+DROP INDEX IF EXISTS idx_bulk_operation_reassignments_op;
+-- +goose StatementEnd
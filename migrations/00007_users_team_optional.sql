@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ALTER COLUMN team_name DROP NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM users WHERE team_name IS NULL;
+ALTER TABLE users ALTER COLUMN team_name SET NOT NULL;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS team_id UUID NOT NULL DEFAULT gen_random_uuid();
+ALTER TABLE teams ADD CONSTRAINT teams_pkey PRIMARY KEY (team_id);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS team_id UUID NULL REFERENCES teams(team_id);
+UPDATE users u SET team_id = t.team_id FROM teams t WHERE t.team_name = u.team_name;
+ALTER TABLE users DROP COLUMN IF EXISTS team_name;
+
+CREATE INDEX IF NOT EXISTS idx_users_team_id ON users(team_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN IF NOT EXISTS team_name TEXT NULL REFERENCES teams(team_name);
+UPDATE users u SET team_name = t.team_name FROM teams t WHERE t.team_id = u.team_id;
+DROP INDEX IF EXISTS idx_users_team_id;
+ALTER TABLE users DROP COLUMN IF EXISTS team_id;
+ALTER TABLE teams DROP CONSTRAINT IF EXISTS teams_pkey;
+ALTER TABLE teams DROP COLUMN IF EXISTS team_id;
+CREATE INDEX IF NOT EXISTS idx_users_team ON users(team_name);
+-- +goose StatementEnd
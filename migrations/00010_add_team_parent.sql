@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS parent_team_id UUID NULL REFERENCES teams(team_id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_teams_parent ON teams(parent_team_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_teams_parent;
+ALTER TABLE teams DROP COLUMN IF EXISTS parent_team_id;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS team_members (
+    team_id    UUID NOT NULL REFERENCES teams(team_id) ON DELETE CASCADE,
+    user_id    UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    is_primary BOOLEAN NOT NULL DEFAULT false,
+    joined_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_team_members_primary ON team_members(user_id) WHERE is_primary;
+CREATE INDEX IF NOT EXISTS idx_team_members_user ON team_members(user_id);
+
+INSERT INTO team_members (team_id, user_id, is_primary)
+SELECT team_id, user_id, true
+FROM users
+WHERE team_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+DROP INDEX IF EXISTS idx_users_team_id;
+ALTER TABLE users DROP COLUMN IF EXISTS team_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN IF NOT EXISTS team_id UUID NULL REFERENCES teams(team_id);
+UPDATE users u SET team_id = m.team_id FROM team_members m WHERE m.user_id = u.user_id AND m.is_primary;
+CREATE INDEX IF NOT EXISTS idx_users_team_id ON users(team_id);
+DROP TABLE IF EXISTS team_members;
+-- +goose StatementEnd
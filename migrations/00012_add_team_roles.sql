@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE team_members ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'member'
+    CHECK (role IN ('lead', 'member', 'observer'));
+
+UPDATE team_members m
+SET role = 'lead'
+FROM teams t
+WHERE t.team_id = m.team_id AND t.lead_user_id = m.user_id;
+
+ALTER TABLE teams DROP COLUMN IF EXISTS lead_user_id;
+
+ALTER TABLE bulk_operations ADD COLUMN IF NOT EXISTS approved_by UUID NULL REFERENCES users(user_id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE bulk_operations DROP COLUMN IF EXISTS approved_by;
+
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS lead_user_id UUID NULL REFERENCES users(user_id) ON DELETE SET NULL;
+UPDATE teams t
+SET lead_user_id = (
+    SELECT m.user_id
+    FROM team_members m
+    WHERE m.team_id = t.team_id AND m.role = 'lead'
+    ORDER BY m.joined_at
+    LIMIT 1
+);
+
+ALTER TABLE team_members DROP COLUMN IF EXISTS role;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE pr_reviewers DROP CONSTRAINT IF EXISTS pr_reviewers_order_index_check;
+ALTER TABLE pr_reviewers ADD CONSTRAINT pr_reviewers_order_index_check CHECK (order_index BETWEEN 1 AND 5);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM pr_reviewers WHERE order_index > 2;
+ALTER TABLE pr_reviewers DROP CONSTRAINT IF EXISTS pr_reviewers_order_index_check;
+ALTER TABLE pr_reviewers ADD CONSTRAINT pr_reviewers_order_index_check CHECK (order_index IN (1, 2));
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE pr_reviewers
+    ADD COLUMN IF NOT EXISTS verdict TEXT NOT NULL DEFAULT 'PENDING'
+        CHECK (verdict IN ('PENDING', 'APPROVED', 'CHANGES_REQUESTED')),
+    ADD COLUMN IF NOT EXISTS decided_at TIMESTAMPTZ NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pr_reviewers
+    DROP COLUMN IF EXISTS decided_at,
+    DROP COLUMN IF EXISTS verdict;
+-- +goose StatementEnd
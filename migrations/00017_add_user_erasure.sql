@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN IF NOT EXISTS erased_at TIMESTAMPTZ NULL;
+
+CREATE TABLE IF NOT EXISTS user_erasures (
+    erasure_id     UUID PRIMARY KEY,
+    user_id        UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    pseudonym      TEXT NOT NULL,
+    approved_by    UUID NULL REFERENCES users(user_id) ON DELETE SET NULL,
+    reason         TEXT NOT NULL DEFAULT '',
+    reassigned_prs INT NOT NULL DEFAULT 0,
+    unresolved_prs INT NOT NULL DEFAULT 0,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_erasures_created ON user_erasures(created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_erasures;
+ALTER TABLE users DROP COLUMN IF EXISTS erased_at;
+-- +goose StatementEnd
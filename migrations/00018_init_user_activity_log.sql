@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS user_activity_log (
+    user_id    UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    is_active  BOOLEAN NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_activity_log_user ON user_activity_log(user_id, changed_at);
+
+-- Users that existed before the log are taken to have had their current
+-- state all along.
+INSERT INTO user_activity_log (user_id, is_active, changed_at)
+SELECT user_id, is_active, '-infinity'::timestamptz FROM users;
+
+CREATE OR REPLACE FUNCTION log_user_activity() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO user_activity_log (user_id, is_active) VALUES (NEW.user_id, NEW.is_active);
+    ELSIF NEW.is_active IS DISTINCT FROM OLD.is_active THEN
+        INSERT INTO user_activity_log (user_id, is_active) VALUES (NEW.user_id, NEW.is_active);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_user_activity_log ON users;
+CREATE TRIGGER trg_user_activity_log
+    AFTER INSERT OR UPDATE OF is_active ON users
+    FOR EACH ROW EXECUTE FUNCTION log_user_activity();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trg_user_activity_log ON users;
+DROP FUNCTION IF EXISTS log_user_activity();
+DROP TABLE IF EXISTS user_activity_log;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS first_assigned_at TIMESTAMPTZ NULL;
+
+-- Reassignments reset pr_reviewers.assigned_at, so for older PRs the earliest
+-- remaining assignment is the best estimate.
+UPDATE pull_requests pr
+SET first_assigned_at = (SELECT MIN(r.assigned_at) FROM pr_reviewers r WHERE r.pull_request_id = pr.pull_request_id)
+WHERE first_assigned_at IS NULL;
+
+CREATE OR REPLACE FUNCTION set_pr_first_assigned_at() RETURNS trigger AS $$
+BEGIN
+    UPDATE pull_requests
+    SET first_assigned_at = NEW.assigned_at
+    WHERE pull_request_id = NEW.pull_request_id AND first_assigned_at IS NULL;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_pr_first_assigned_at ON pr_reviewers;
+CREATE TRIGGER trg_pr_first_assigned_at
+    AFTER INSERT ON pr_reviewers
+    FOR EACH ROW EXECUTE FUNCTION set_pr_first_assigned_at();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trg_pr_first_assigned_at ON pr_reviewers;
+DROP FUNCTION IF EXISTS set_pr_first_assigned_at();
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS first_assigned_at;
+-- +goose StatementEnd